@@ -0,0 +1,227 @@
+package setup
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// crossFieldCheck is one cross-field rule applied to one field.
+type crossFieldCheck struct {
+	structValue reflect.Value
+	fieldValue  reflect.Value
+	prefix      string
+	path        string
+	rule        string
+	argument    string
+}
+
+// crossFieldRules maps validate rule names to checks that read other fields
+// of the same struct.
+var crossFieldRules = map[string]func(check crossFieldCheck) error{
+	"requiredWith": checkRequiredWith,
+	"excludedWith": checkExcludedWith,
+	"eqField":      compareFields("eqField", "equal to", func(order int) bool { return order == 0 }),
+	"neField":      compareFields("neField", "different from", func(order int) bool { return order != 0 }),
+	"ltField":      compareFields("ltField", "less than", func(order int) bool { return order < 0 }),
+	"lteField":     compareFields("lteField", "less than or equal to", func(order int) bool { return order <= 0 }),
+	"gtField":      compareFields("gtField", "greater than", func(order int) bool { return order > 0 }),
+	"gteField":     compareFields("gteField", "greater than or equal to", func(order int) bool { return order >= 0 }),
+}
+
+// sibling resolves a field name or dotted path relative to the struct that
+// holds the checked field, returning the value and its full path.
+func (check crossFieldCheck) sibling(name string) (reflect.Value, string, error) {
+	path := name
+	if check.prefix != "" {
+		path = check.prefix + "." + name
+	}
+	value, ok := valueAtPath(check.structValue, name)
+	if !ok {
+		return reflect.Value{}, path, NewValidationFailedError(check.path, check.rule, fmt.Sprintf("unknown field %q", name))
+	}
+	return value, path, nil
+}
+
+// setSiblings returns the paths of the fields named in the rule argument that
+// hold a non-empty value.
+func (check crossFieldCheck) setSiblings() ([]string, error) {
+	var set []string
+	for _, name := range strings.Split(check.argument, "|") {
+		value, path, err := check.sibling(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if !isEmpty(value) {
+			set = append(set, path)
+		}
+	}
+	return set, nil
+}
+
+func checkRequiredWith(check crossFieldCheck) error {
+	set, err := check.setSiblings()
+	if err != nil || len(set) == 0 || !isEmpty(check.fieldValue) {
+		return err
+	}
+	return NewCrossFieldValidationFailedError(check.path, check.rule, set, fmt.Sprintf("value is required when %s is set", strings.Join(set, ", ")))
+}
+
+func checkExcludedWith(check crossFieldCheck) error {
+	set, err := check.setSiblings()
+	if err != nil || len(set) == 0 || isEmpty(check.fieldValue) {
+		return err
+	}
+	return NewCrossFieldValidationFailedError(check.path, check.rule, set, fmt.Sprintf("value must be empty when %s is set", strings.Join(set, ", ")))
+}
+
+// compareFields builds a comparison rule. accept receives -1, 0 or 1 as the
+// checked field is less than, equal to or greater than the other field. The
+// rule is skipped when either side is a nil pointer.
+func compareFields(name string, relation string, accept func(order int) bool) func(check crossFieldCheck) error {
+	return func(check crossFieldCheck) error {
+		other, otherPath, err := check.sibling(check.argument)
+		if err != nil {
+			return err
+		}
+		left, leftOK := dereference(check.fieldValue)
+		right, rightOK := dereference(other)
+		if !leftOK || !rightOK {
+			return nil
+		}
+		order, comparable := compareValues(left, right)
+		if !comparable {
+			reason := fmt.Sprintf("%s cannot compare %s with %s", name, left.Type(), right.Type())
+			return NewCrossFieldValidationFailedError(check.path, check.rule, []string{otherPath}, reason)
+		}
+		if accept(order) {
+			return nil
+		}
+		reason := fmt.Sprintf("value %v is not %s %s (%v)", left.Interface(), relation, otherPath, right.Interface())
+		return NewCrossFieldValidationFailedError(check.path, check.rule, []string{otherPath}, reason)
+	}
+}
+
+func dereference(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
+
+// compareValues orders two numbers, two strings or two bools. It reports
+// false for any other combination.
+func compareValues(left reflect.Value, right reflect.Value) (int, bool) {
+	leftNumber, leftIsNumber := numberOf(left)
+	rightNumber, rightIsNumber := numberOf(right)
+	switch {
+	case leftIsNumber && rightIsNumber:
+		return compareOrdered(leftNumber, rightNumber), true
+	case left.Kind() == reflect.String && right.Kind() == reflect.String:
+		return strings.Compare(left.String(), right.String()), true
+	case left.Kind() == reflect.Bool && right.Kind() == reflect.Bool:
+		if left.Bool() == right.Bool() {
+			return 0, true
+		}
+		if right.Bool() {
+			return -1, true
+		}
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+func numberOf(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func compareOrdered(left float64, right float64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// oneOfAlternative is a set of fields that together count as one choice.
+type oneOfAlternative struct {
+	label string
+	paths []string
+	set   bool
+}
+
+type oneOfGroup struct {
+	name         string
+	alternatives []oneOfAlternative
+}
+
+// oneOfGroups collects the oneOf tags of one struct in declaration order.
+type oneOfGroups []oneOfGroup
+
+// add records a field tagged oneOf:"group" or oneOf:"group:label". Fields
+// without a label are an alternative of their own.
+func (groups *oneOfGroups) add(tag string, fieldName string, path string, set bool) {
+	name, label, hasLabel := strings.Cut(tag, ":")
+	if !hasLabel {
+		label = fieldName
+	}
+	group := groups.group(name)
+	for i := range group.alternatives {
+		if group.alternatives[i].label == label {
+			group.alternatives[i].paths = append(group.alternatives[i].paths, path)
+			group.alternatives[i].set = group.alternatives[i].set || set
+			return
+		}
+	}
+	group.alternatives = append(group.alternatives, oneOfAlternative{label: label, paths: []string{path}, set: set})
+}
+
+func (groups *oneOfGroups) group(name string) *oneOfGroup {
+	for i := range *groups {
+		if (*groups)[i].name == name {
+			return &(*groups)[i]
+		}
+	}
+	*groups = append(*groups, oneOfGroup{name: name})
+	return &(*groups)[len(*groups)-1]
+}
+
+// check reports every group in which not exactly one alternative is set. The
+// error is attached to the first field of the group and names all of them.
+func (groups oneOfGroups) check(violations *[]error) {
+	for _, group := range groups {
+		var all, described, chosen []string
+		for _, alternative := range group.alternatives {
+			all = append(all, alternative.paths...)
+			described = append(described, strings.Join(alternative.paths, "+"))
+			if alternative.set {
+				chosen = append(chosen, strings.Join(alternative.paths, "+"))
+			}
+		}
+		if len(chosen) == 1 {
+			continue
+		}
+		got := "none is"
+		if len(chosen) > 1 {
+			got = strings.Join(chosen, " and ") + " are"
+		}
+		reason := fmt.Sprintf("exactly one of %s must be set, %s", strings.Join(described, ", "), got)
+		*violations = append(*violations, NewCrossFieldValidationFailedError(all[0], "oneOf="+group.name, all[1:], reason))
+	}
+}
@@ -0,0 +1,75 @@
+package setup_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+)
+
+func TestValidate_CrossFieldRules(t *testing.T) {
+	type PoolConfiguration struct {
+		Name     string `validate:"neField=TLS.CertFile"`
+		Token    string `oneOf:"auth"`
+		Username string `oneOf:"auth:basic" validate:"requiredWith=Password"`
+		Password string `oneOf:"auth:basic" validate:"requiredWith=Username"`
+		MaxConns *int
+		TLS      struct {
+			CertFile string `validate:"requiredWith=Enabled"`
+			KeyFile  string `validate:"excludedWith=Insecure|Plain"`
+			Enabled  bool
+			Insecure bool
+			Plain    bool
+		}
+		MinConns int `validate:"lteField=MaxConns,gtField=Floor"`
+		Floor    float64
+	}
+
+	maxConns := 4
+	valid := &PoolConfiguration{Name: "pool", Token: "t", MinConns: 4, MaxConns: &maxConns}
+	valid.TLS.Enabled = true
+	valid.TLS.CertFile = "cert.pem"
+	require.NoError(t, pkg.Validate(valid))
+
+	invalid := &PoolConfiguration{Name: "same", Token: "t", Username: "u", MinConns: 5, MaxConns: &maxConns, Floor: 5}
+	invalid.TLS.Enabled = true
+	invalid.TLS.KeyFile = "key.pem"
+	invalid.TLS.Plain = true
+	invalid.TLS.CertFile = "same"
+	validationError := pkg.Validate(invalid)
+	require.Error(t, validationError)
+	message := validationError.Error()
+	assert.Contains(t, message, "field Name: neField=TLS.CertFile: value same is not different from TLS.CertFile (same)")
+	assert.Contains(t, message, "field Password: requiredWith=Username: value is required when Username is set")
+	assert.Contains(t, message, "field TLS.KeyFile: excludedWith=Insecure|Plain: value must be empty when TLS.Plain is set")
+	assert.Contains(t, message, "field MinConns: lteField=MaxConns: value 5 is not less than or equal to MaxConns (4)")
+	assert.Contains(t, message, "field MinConns: gtField=Floor: value 5 is not greater than Floor (5)")
+	assert.Contains(t, message, "field Token: oneOf=auth: exactly one of Token, Username+Password must be set, Token and Username+Password are")
+	assert.NotContains(t, message, "CertFile: requiredWith")
+
+	var crossFieldError *pkg.ValidationFailedError
+	require.True(t, errors.As(validationError, &crossFieldError))
+	assert.Equal(t, "Name", crossFieldError.Path)
+	assert.Equal(t, []string{"TLS.CertFile"}, crossFieldError.Related)
+
+	none := &PoolConfiguration{Name: "x"}
+	none.TLS.Enabled = true
+	validationError = pkg.Validate(none)
+	require.Error(t, validationError)
+	message = validationError.Error()
+	assert.Contains(t, message, "field TLS.CertFile: requiredWith=Enabled: value is required when TLS.Enabled is set")
+	assert.Contains(t, message, "exactly one of Token, Username+Password must be set, none is")
+	assert.NotContains(t, message, "lteField")
+
+	type BrokenConfiguration struct {
+		Name string `validate:"eqField=Missing"`
+		Port int    `validate:"ltField=Name"`
+	}
+	validationError = pkg.Validate(&BrokenConfiguration{})
+	require.Error(t, validationError)
+	assert.Contains(t, validationError.Error(), "field Name: eqField=Missing: unknown field \"Missing\"")
+	assert.Contains(t, validationError.Error(), "field Port: ltField=Name: ltField cannot compare int with string")
+}
@@ -3,6 +3,8 @@ package setup
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -10,12 +12,25 @@ var (
 	ErrLoadAggregatedFailed = errors.New("aggregated load failed")
 	ErrInvalidTarget        = errors.New("invalid target")
 	ErrSourceFieldFailed    = errors.New("source field failed")
+	ErrDotenvSyntax         = errors.New("dotenv syntax error")
+	ErrHelpRequested        = errors.New("help requested")
+	ErrFlagRepeated         = errors.New("flag repeated")
+	ErrArgMissing           = errors.New("positional argument missing")
+	ErrArgUnexpected        = errors.New("unexpected positional arguments")
+	ErrCommandMissing       = errors.New("command missing")
+	ErrCommandUnknown       = errors.New("unknown command")
+	ErrCompletionRequested  = errors.New("completion requested")
+	ErrShellUnsupported     = errors.New("unsupported shell")
+	ErrValidationFailed     = errors.New("validation failed")
+	ErrRequiredMissing      = errors.New("required field missing")
+	ErrHookFailed           = errors.New("hook failed")
 )
 
 type LoaderSourceFailedError struct {
 	OriginalError error
 	SourceName    string
 	SourceIndex   int
+	Interrupted   bool
 }
 
 func NewLoaderSourceFailedError(sourceIndex int, sourceName string, originalError error) error {
@@ -23,7 +38,18 @@ func NewLoaderSourceFailedError(sourceIndex int, sourceName string, originalErro
 	return fmt.Errorf("%w: %w", ErrLoaderSourceFailed, typedError)
 }
 
+// NewLoaderSourceInterruptedError reports a source that did not run to
+// completion because the load context was done. originalError is the context
+// error, or the source's own error when it stopped because of the context.
+func NewLoaderSourceInterruptedError(sourceIndex int, sourceName string, originalError error) error {
+	typedError := &LoaderSourceFailedError{SourceIndex: sourceIndex, SourceName: sourceName, OriginalError: originalError, Interrupted: true}
+	return fmt.Errorf("%w: %w", ErrLoaderSourceFailed, typedError)
+}
+
 func (loaderSourceFailedError *LoaderSourceFailedError) Error() string {
+	if loaderSourceFailedError.Interrupted {
+		return fmt.Sprintf("source at index %d named %s interrupted: %v", loaderSourceFailedError.SourceIndex, loaderSourceFailedError.SourceName, loaderSourceFailedError.OriginalError)
+	}
 	return fmt.Sprintf("source at index %d named %s failed: %v", loaderSourceFailedError.SourceIndex, loaderSourceFailedError.SourceName, loaderSourceFailedError.OriginalError)
 }
 
@@ -61,12 +87,168 @@ func (invalidTargetError *InvalidTargetError) Error() string {
 	return fmt.Sprintf("invalid target: %s", invalidTargetError.Reason)
 }
 
+type DotenvSyntaxError struct {
+	Reason string
+	Line   int
+}
+
+func NewDotenvSyntaxError(line int, reason string) error {
+	typedError := &DotenvSyntaxError{Line: line, Reason: reason}
+	return fmt.Errorf("%w: %w", ErrDotenvSyntax, typedError)
+}
+
+func (dotenvSyntaxError *DotenvSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", dotenvSyntaxError.Line, dotenvSyntaxError.Reason)
+}
+
+type HelpRequestedError struct {
+	Usage string
+}
+
+func NewHelpRequestedError(usage string) error {
+	typedError := &HelpRequestedError{Usage: usage}
+	return fmt.Errorf("%w: %w", ErrHelpRequested, typedError)
+}
+
+func (helpRequestedError *HelpRequestedError) Error() string {
+	return helpRequestedError.Usage
+}
+
+type CompletionRequestedError struct {
+	Shell  string
+	Script string
+}
+
+func NewCompletionRequestedError(shell string, script string) error {
+	typedError := &CompletionRequestedError{Shell: shell, Script: script}
+	return fmt.Errorf("%w: %w", ErrCompletionRequested, typedError)
+}
+
+func (completionRequestedError *CompletionRequestedError) Error() string {
+	return completionRequestedError.Script
+}
+
+type ArgMissingError struct {
+	Path  string
+	Index int
+}
+
+func NewArgMissingError(index int, path string) error {
+	typedError := &ArgMissingError{Index: index, Path: path}
+	return fmt.Errorf("%w: %w", ErrArgMissing, typedError)
+}
+
+func (argMissingError *ArgMissingError) Error() string {
+	return fmt.Sprintf("argument %d for field %s is required", argMissingError.Index, argMissingError.Path)
+}
+
+type ArgUnexpectedError struct {
+	Values []string
+	Index  int
+}
+
+func NewArgUnexpectedError(index int, values []string) error {
+	typedError := &ArgUnexpectedError{Index: index, Values: values}
+	return fmt.Errorf("%w: %w", ErrArgUnexpected, typedError)
+}
+
+func (argUnexpectedError *ArgUnexpectedError) Error() string {
+	return fmt.Sprintf("%d extra arguments starting at index %d: %q", len(argUnexpectedError.Values), argUnexpectedError.Index, argUnexpectedError.Values)
+}
+
+type CommandError struct {
+	Name     string
+	Commands []string
+}
+
+func NewCommandMissingError(commands []string) error {
+	typedError := &CommandError{Commands: commands}
+	return fmt.Errorf("%w: %w", ErrCommandMissing, typedError)
+}
+
+func NewCommandUnknownError(name string, commands []string) error {
+	typedError := &CommandError{Name: name, Commands: commands}
+	return fmt.Errorf("%w: %w", ErrCommandUnknown, typedError)
+}
+
+func (commandError *CommandError) Error() string {
+	if commandError.Name == "" {
+		return fmt.Sprintf("expected one of: %s", strings.Join(commandError.Commands, ", "))
+	}
+	return fmt.Sprintf("%q is not one of: %s", commandError.Name, strings.Join(commandError.Commands, ", "))
+}
+
+type ValidationFailedError struct {
+	Path    string
+	Rule    string
+	Reason  string
+	Related []string
+}
+
+func NewValidationFailedError(path string, rule string, reason string) error {
+	typedError := &ValidationFailedError{Path: path, Rule: rule, Reason: reason}
+	return fmt.Errorf("%w: %w", ErrValidationFailed, typedError)
+}
+
+// NewCrossFieldValidationFailedError reports a rule that involves other
+// fields besides path; related lists their paths.
+func NewCrossFieldValidationFailedError(path string, rule string, related []string, reason string) error {
+	typedError := &ValidationFailedError{Path: path, Rule: rule, Reason: reason, Related: related}
+	return fmt.Errorf("%w: %w", ErrValidationFailed, typedError)
+}
+
+func (validationFailedError *ValidationFailedError) Error() string {
+	return fmt.Sprintf("field %s: %s: %s", validationFailedError.Path, validationFailedError.Rule, validationFailedError.Reason)
+}
+
+type RequiredMissingError struct {
+	Path string
+	Keys []string
+}
+
+func NewRequiredMissingError(path string, keys []string) error {
+	typedError := &RequiredMissingError{Path: path, Keys: keys}
+	return fmt.Errorf("%w: %w", ErrRequiredMissing, typedError)
+}
+
+func (requiredMissingError *RequiredMissingError) Error() string {
+	if len(requiredMissingError.Keys) == 0 {
+		return fmt.Sprintf("field %s is required but no source can provide it", requiredMissingError.Path)
+	}
+	return fmt.Sprintf("field %s is required; set one of: %s", requiredMissingError.Path, strings.Join(requiredMissingError.Keys, ", "))
+}
+
+type HookFailedError struct {
+	OriginalError error
+	Hook          string
+	Path          string
+}
+
+func NewHookFailedError(hook string, path string, originalError error) error {
+	typedError := &HookFailedError{Hook: hook, Path: path, OriginalError: originalError}
+	return fmt.Errorf("%w: %w", ErrHookFailed, typedError)
+}
+
+func (hookFailedError *HookFailedError) Error() string {
+	if hookFailedError.Path == "" {
+		return fmt.Sprintf("%s: %v", hookFailedError.Hook, hookFailedError.OriginalError)
+	}
+	return fmt.Sprintf("field %s: %s: %v", hookFailedError.Path, hookFailedError.Hook, hookFailedError.OriginalError)
+}
+
+func (hookFailedError *HookFailedError) Unwrap() error {
+	return hookFailedError.OriginalError
+}
+
 type SourceFieldFailedError struct {
 	OriginalError error
 	SourceName    string
 	Key           string
 	Value         string
 	Path          string
+	File          string
+	Line          int
+	Column        int
 }
 
 func NewEnvFieldFailedError(key string, value string, path string, originalError error) error {
@@ -74,6 +256,13 @@ func NewEnvFieldFailedError(key string, value string, path string, originalError
 	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
 }
 
+// NewDotenvFieldFailedError reports a dotenv entry that could not be assigned,
+// with the file and line the entry was read from.
+func NewDotenvFieldFailedError(file string, line int, key string, value string, path string, originalError error) error {
+	typedError := &SourceFieldFailedError{SourceName: "dotenv", File: file, Line: line, Key: key, Value: value, Path: path, OriginalError: originalError}
+	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
+}
+
 func NewDictFieldFailedError(path string, originalError error) error {
 	typedError := &SourceFieldFailedError{SourceName: "dict", Path: path, OriginalError: originalError}
 	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
@@ -84,24 +273,51 @@ func NewFlagsFieldFailedError(name string, value string, path string, originalEr
 	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
 }
 
+func NewArgFieldFailedError(index int, value string, path string, originalError error) error {
+	typedError := &SourceFieldFailedError{SourceName: "args", Key: strconv.Itoa(index), Value: value, Path: path, OriginalError: originalError}
+	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
+}
+
 func NewJSONFieldFailedError(path string, originalError error) error {
 	typedError := &SourceFieldFailedError{SourceName: "json", Path: path, OriginalError: originalError}
 	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
 }
 
+func NewYAMLFieldFailedError(path string, line int, column int, originalError error) error {
+	typedError := &SourceFieldFailedError{SourceName: "yaml", Path: path, Line: line, Column: column, OriginalError: originalError}
+	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
+}
+
+func NewTOMLFieldFailedError(key string, path string, originalError error) error {
+	typedError := &SourceFieldFailedError{SourceName: "toml", Key: key, Path: path, OriginalError: originalError}
+	return fmt.Errorf("%w: %w", ErrSourceFieldFailed, typedError)
+}
+
 func (e *SourceFieldFailedError) Error() string {
 	if e.SourceName == "env" {
 		return fmt.Sprintf("env %s=%s field %s: %v", e.Key, e.Value, e.Path, e.OriginalError)
 	}
+	if e.SourceName == "dotenv" {
+		return fmt.Sprintf("dotenv %s:%d %s=%s field %s: %v", e.File, e.Line, e.Key, e.Value, e.Path, e.OriginalError)
+	}
 	if e.SourceName == "flags" {
 		if e.Path != "" {
 			return fmt.Sprintf("flags %s=%s field %s: %v", e.Key, e.Value, e.Path, e.OriginalError)
 		}
 		return fmt.Sprintf("flags %s=%s: %v", e.Key, e.Value, e.OriginalError)
 	}
+	if e.SourceName == "args" {
+		return fmt.Sprintf("argument %s=%s field %s: %v", e.Key, e.Value, e.Path, e.OriginalError)
+	}
 	if e.SourceName == "dict" {
 		return fmt.Sprintf("dict field %s: %v", e.Path, e.OriginalError)
 	}
+	if e.SourceName == "toml" {
+		return fmt.Sprintf("toml key %s field %s: %v", e.Key, e.Path, e.OriginalError)
+	}
+	if e.SourceName == "yaml" {
+		return fmt.Sprintf("yaml field %s at line %d, column %d: %v", e.Path, e.Line, e.Column, e.OriginalError)
+	}
 	return fmt.Sprintf("json field %s: %v", e.Path, e.OriginalError)
 }
 
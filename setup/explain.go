@@ -0,0 +1,259 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ExplainStep is what one source did, or would have done, with a field.
+type ExplainStep struct {
+	// Err is the conversion or assignment error, if any.
+	Err error
+	// Value is the field value right after the source assigned it.
+	Value any
+	// SourceType is the Go type of the source, e.g. "*env.Source".
+	SourceType string
+	// Key is what the source looked up: env var, flag name, JSON path.
+	Key string
+	// SourceIndex is the position of the source in the loader.
+	SourceIndex int
+	// Found reports whether the source had a value under Key.
+	Found bool
+	// Eligible reports whether the load mode let the source write the field.
+	Eligible bool
+	// Default reports whether the value came from a default tag.
+	Default bool
+	// Assigned reports whether the source wrote the field.
+	Assigned bool
+}
+
+// FieldExplanation lists the steps every observable source took for a field,
+// in loader order.
+type FieldExplanation struct {
+	// Path is the field path, e.g. "Database.Port".
+	Path string
+	// Steps holds one entry per source that examined the field.
+	Steps []ExplainStep
+}
+
+// Winner returns the step of the last source that assigned the field.
+func (field FieldExplanation) Winner() (ExplainStep, bool) {
+	for i := len(field.Steps) - 1; i >= 0; i-- {
+		if field.Steps[i].Assigned {
+			return field.Steps[i], true
+		}
+	}
+	return ExplainStep{}, false
+}
+
+// Explanation is the per-field trace produced by Loader.Explain. Fields are
+// listed in declaration order.
+type Explanation struct {
+	index  map[string]int
+	fields []FieldExplanation
+}
+
+// Fields returns every leaf field of the target, examined or not.
+func (explanation Explanation) Fields() []FieldExplanation {
+	return explanation.fields
+}
+
+// Field returns the trace of the field at path.
+func (explanation Explanation) Field(path string) (FieldExplanation, bool) {
+	position, ok := explanation.index[path]
+	if !ok {
+		return FieldExplanation{}, false
+	}
+	return explanation.fields[position], true
+}
+
+// String renders every field with one line per step and the winner.
+func (explanation Explanation) String() string {
+	var builder strings.Builder
+	for _, field := range explanation.fields {
+		builder.WriteString(field.Path + ":\n")
+		for _, step := range field.Steps {
+			builder.WriteString(fmt.Sprintf("  source %d (%s) key %s: %s\n", step.SourceIndex, step.SourceType, step.Key, describeStep(step)))
+		}
+		if winner, ok := field.Winner(); ok {
+			builder.WriteString(fmt.Sprintf("  winner: source %d\n", winner.SourceIndex))
+		} else {
+			builder.WriteString("  winner: none\n")
+		}
+	}
+	return builder.String()
+}
+
+func describeStep(step ExplainStep) string {
+	found := "not found"
+	if step.Found {
+		found = "found"
+	}
+	switch {
+	case !step.Eligible && !step.Found:
+		return found
+	case !step.Eligible:
+		return found + ", skipped by mode"
+	case step.Err != nil:
+		return fmt.Sprintf("%s, cast failed: %v", found, step.Err)
+	case step.Assigned && step.Default:
+		return fmt.Sprintf("%s, assigned default %v", found, step.Value)
+	case step.Assigned:
+		return fmt.Sprintf("%s, assigned %v", found, step.Value)
+	default:
+		return found + ", nothing to assign"
+	}
+}
+
+// SideEffectSource is implemented by sources that write somewhere besides the
+// target, such as the flags source storing positional arguments through
+// flags.WithPositional. WithoutSideEffects returns a copy that loads the
+// target the same way but leaves everything else alone.
+type SideEffectSource interface {
+	Source
+	WithoutSideEffects() Source
+}
+
+// Explain runs every source against a deep copy of cfg and reports, per leaf
+// field, what each source did: the key it looked up, whether it found a
+// value, whether the load mode allowed the write, and the cast result or
+// error. cfg itself is never modified, and sources implementing
+// SideEffectSource run without their side effects; other sources that write
+// outside the target still do. The returned error is the one Load would have
+// returned. Sources that do not implement ObservableSource still run, so
+// later sources see their effect, but they contribute no steps.
+func (l *Loader) Explain(cfg any) (Explanation, error) {
+	explanation := Explanation{index: make(map[string]int)}
+	walkLeafPaths(cfg, func(path string, _ reflect.StructField) {
+		explanation.field(path)
+	})
+	target := cfg
+	value := reflect.ValueOf(cfg)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		target = deepCopy(value).Interface()
+	}
+	dryRun := &Loader{sources: make([]Source, len(l.sources))}
+	for i, source := range l.sources {
+		if sideEffects, ok := source.(SideEffectSource); ok {
+			source = sideEffects.WithoutSideEffects()
+		}
+		dryRun.sources[i] = source
+	}
+	err := dryRun.load(context.Background(), target, func(sourceIndex int, sourceType string) FieldObserver {
+		return FieldObserverFunc(func(event FieldEvent) {
+			step := ExplainStep{
+				Err:         event.Err,
+				SourceType:  sourceType,
+				Key:         event.Key,
+				SourceIndex: sourceIndex,
+				Found:       event.Found,
+				Eligible:    event.Eligible,
+				Default:     event.Default,
+				Assigned:    event.Assigned,
+			}
+			if event.Assigned {
+				if fieldValue, ok := valueAtPath(reflect.ValueOf(target), event.Path); ok {
+					step.Value = fieldValue.Interface()
+				}
+			}
+			field := explanation.field(event.Path)
+			field.Steps = append(field.Steps, step)
+		})
+	})
+	return explanation, err
+}
+
+// field returns the entry for path, adding it when a source reports a path
+// the type walk did not produce.
+func (explanation *Explanation) field(path string) *FieldExplanation {
+	position, ok := explanation.index[path]
+	if !ok {
+		position = len(explanation.fields)
+		explanation.index[path] = position
+		explanation.fields = append(explanation.fields, FieldExplanation{Path: path})
+	}
+	return &explanation.fields[position]
+}
+
+// valueAtPath follows a dotted field path from root, dereferencing pointers
+// on the way. It reports false when a pointer on the path is nil.
+func valueAtPath(root reflect.Value, path string) (reflect.Value, bool) {
+	current := root
+	for _, name := range strings.Split(path, ".") {
+		for current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				return reflect.Value{}, false
+			}
+			current = current.Elem()
+		}
+		if current.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		current = current.FieldByName(name)
+		if !current.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return current, true
+}
+
+// deepCopy returns a copy of value that shares no pointers, slices or maps
+// reachable through exported fields. Unexported fields are copied shallowly,
+// since sources never write them.
+func deepCopy(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		copied := reflect.New(value.Type().Elem())
+		copied.Elem().Set(deepCopy(value.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			copied.Field(i).Set(deepCopy(value.Field(i)))
+		}
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopy(value.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopy(value.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			copied.SetMapIndex(iterator.Key(), deepCopy(iterator.Value()))
+		}
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(deepCopy(value.Elem()))
+		return copied
+	default:
+		return value
+	}
+}
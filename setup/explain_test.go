@@ -0,0 +1,78 @@
+package setup_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/dict"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	"github.com/Sufir/go-set-me-up/setup/source/flags"
+)
+
+func TestLoader_Explain_TracesEverySourceWithoutMutatingTarget(t *testing.T) {
+	type ExplainedConfiguration struct {
+		Database *struct {
+			Host string `env:"DB_HOST"`
+		}
+		Name  string   `env:"NAME" flag:"name"`
+		Level string   `flag:"level" flagDefault:"info"`
+		Tags  []string `env:"TAGS"`
+		Port  int      `env:"PORT" flag:"port"`
+	}
+
+	t.Setenv("APP_PORT", "9000")
+	t.Setenv("APP_NAME", "from-env")
+	t.Setenv("APP_TAGS", "a,b")
+	t.Setenv("APP_DATABASE_DB_HOST", "db")
+	loader := pkg.NewLoader(
+		dict.NewSource(map[string]any{"Port": 8080}, pkg.ModeOverride),
+		env.NewSource("app", ",", pkg.ModeFillMissing),
+		flags.NewSourceWithArgs(pkg.ModeOverride, []string{"--port", "x"}),
+	)
+	configuration := &ExplainedConfiguration{Name: "preset", Tags: []string{"keep"}}
+	configuration.Database = &struct {
+		Host string `env:"DB_HOST"`
+	}{Host: ""}
+
+	explanation, explainError := loader.Explain(configuration)
+	require.Error(t, explainError)
+	assert.True(t, errors.Is(explainError, pkg.ErrLoadAggregatedFailed))
+	assert.Equal(t, &ExplainedConfiguration{
+		Database: &struct {
+			Host string `env:"DB_HOST"`
+		}{},
+		Name: "preset",
+		Tags: []string{"keep"},
+	}, configuration)
+
+	port, ok := explanation.Field("Port")
+	require.True(t, ok)
+	require.Len(t, port.Steps, 3)
+	assert.Equal(t, pkg.ExplainStep{SourceType: "*dict.Source", Key: "Port", SourceIndex: 0, Found: true, Eligible: true, Assigned: true, Value: 8080}, port.Steps[0])
+	assert.Equal(t, pkg.ExplainStep{SourceType: "*env.Source", Key: "APP_PORT", SourceIndex: 1, Found: true}, port.Steps[1])
+	assert.Equal(t, "--port", port.Steps[2].Key)
+	assert.True(t, port.Steps[2].Eligible)
+	assert.False(t, port.Steps[2].Assigned)
+	require.Error(t, port.Steps[2].Err)
+	winner, ok := port.Winner()
+	require.True(t, ok)
+	assert.Equal(t, 0, winner.SourceIndex)
+
+	host, _ := explanation.Field("Database.Host")
+	require.Len(t, host.Steps, 1)
+	assert.Equal(t, "db", host.Steps[0].Value)
+
+	name, _ := explanation.Field("Name")
+	_, ok = name.Winner()
+	assert.False(t, ok)
+
+	report := explanation.String()
+	assert.Contains(t, report, "Port:\n  source 0 (*dict.Source) key Port: found, assigned 8080\n  source 1 (*env.Source) key APP_PORT: found, skipped by mode\n")
+	assert.Contains(t, report, "  winner: source 0\n")
+	assert.Contains(t, report, "Level:\n  source 2 (*flags.Source) key --level: not found, assigned default info\n  winner: source 2\n")
+	assert.Contains(t, report, "Name:\n  source 1 (*env.Source) key APP_NAME: found, skipped by mode\n  source 2 (*flags.Source) key --name: not found\n  winner: none\n")
+}
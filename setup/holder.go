@@ -0,0 +1,49 @@
+package setup
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Holder publishes a configuration value that readers can use while it is
+// being reloaded. Every load writes into a fresh T, and only a value that
+// loaded without errors is published, so readers never see a partially
+// loaded or invalid configuration.
+type Holder[T any] struct {
+	loader  *Loader
+	current atomic.Pointer[T]
+	mu      sync.Mutex
+}
+
+// NewHolder loads the initial value and returns a Holder publishing it. It
+// fails when the initial load fails.
+func NewHolder[T any](loader *Loader) (*Holder[T], error) {
+	holder := &Holder[T]{loader: loader}
+	initial := new(T)
+	if err := loader.Load(initial); err != nil {
+		return nil, err
+	}
+	holder.current.Store(initial)
+	return holder, nil
+}
+
+// Get returns the published value. The pointer is shared by all readers and
+// the Holder never writes through it, but it is not a copy: callers must
+// treat it as read-only, or a write becomes visible to every other reader.
+func (holder *Holder[T]) Get() *T {
+	return holder.current.Load()
+}
+
+// Reload loads into a fresh value and publishes it. When loading fails the
+// last good value stays published and the load error is returned. Concurrent
+// calls are serialized.
+func (holder *Holder[T]) Reload() error {
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	fresh := new(T)
+	if err := holder.loader.Load(fresh); err != nil {
+		return err
+	}
+	holder.current.Store(fresh)
+	return nil
+}
@@ -0,0 +1,87 @@
+package setup_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+)
+
+func TestHolder_ReloadPublishesFreshValuesAndKeepsLastGood(t *testing.T) {
+	type HeldConfiguration struct {
+		Name string `env:"NAME" validate:"required"`
+		Port int    `env:"PORT" validate:"min=1"`
+	}
+	t.Setenv("APP_NAME", "first")
+	t.Setenv("APP_PORT", "80")
+	holder, holderError := pkg.NewHolder[HeldConfiguration](pkg.NewLoader(env.NewSource("app", ",", pkg.ModeOverride)))
+	require.NoError(t, holderError)
+	first := holder.Get()
+	assert.Equal(t, HeldConfiguration{Name: "first", Port: 80}, *first)
+
+	t.Setenv("APP_PORT", "81")
+	require.NoError(t, holder.Reload())
+	assert.Equal(t, 81, holder.Get().Port)
+	assert.Equal(t, 80, first.Port)
+
+	t.Setenv("APP_PORT", "x")
+	reloadError := holder.Reload()
+	require.Error(t, reloadError)
+	assert.True(t, errors.Is(reloadError, pkg.ErrLoadAggregatedFailed))
+	assert.Equal(t, 81, holder.Get().Port)
+
+	t.Setenv("APP_PORT", "0")
+	require.Error(t, holder.Reload())
+	assert.Equal(t, 81, holder.Get().Port)
+
+	t.Setenv("APP_NAME", "")
+	_, holderError = pkg.NewHolder[HeldConfiguration](pkg.NewLoader(env.NewSource("app", ",", pkg.ModeOverride)))
+	require.Error(t, holderError)
+}
+
+func TestHolder_ConcurrentReadersAndReloaders(t *testing.T) {
+	type HeldConfiguration struct {
+		Tags  []string
+		Count int
+	}
+	var counter atomic.Int64
+	loader := pkg.NewLoader(sourceFunc(func(target any) error {
+		value := int(counter.Add(1))
+		configuration := target.(*HeldConfiguration)
+		configuration.Count = value
+		configuration.Tags = []string{strconv.Itoa(value)}
+		return nil
+	}))
+	holder, holderError := pkg.NewHolder[HeldConfiguration](loader)
+	require.NoError(t, holderError)
+
+	var group sync.WaitGroup
+	for reader := 0; reader < 8; reader++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for i := 0; i < 500; i++ {
+				snapshot := holder.Get()
+				assert.Equal(t, strconv.Itoa(snapshot.Count), snapshot.Tags[0])
+			}
+		}()
+	}
+	for reloader := 0; reloader < 4; reloader++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for i := 0; i < 50; i++ {
+				assert.NoError(t, holder.Reload())
+			}
+		}()
+	}
+	group.Wait()
+	assert.Equal(t, 201, holder.Get().Count)
+}
@@ -0,0 +1,293 @@
+package setup
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Defaulter is implemented by configuration types that set their own
+// defaults. Loader calls SetDefaults before any source runs. When an
+// ObservableSource allocates a struct behind a nil pointer, the defaults are
+// filled into its zero fields that the source did not assign right after that
+// source. Other sources cannot tell an explicit zero from a missing value, so
+// structs they allocate get no defaults.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Finalizer is implemented by configuration types that derive computed
+// fields. Loader calls Finalize after all sources and before validation.
+type Finalizer interface {
+	Finalize() error
+}
+
+// Validator is implemented by configuration types that check their own
+// invariants. Loader calls Validate after all sources and after validate
+// tags have been checked.
+type Validator interface {
+	Validate() error
+}
+
+// visitHooks calls visit for the struct cfg points to and every nested struct
+// reachable through exported fields, including the elements of slices, arrays
+// and maps, nested ones first so that an outer type can rely on, or override,
+// what its fields did. Element paths carry the index or key, as in
+// "Upstreams[0]" or "Upstreams[main]". Nil pointers are not allocated and are
+// skipped, as are structs implementing encoding.TextUnmarshaler. Struct values
+// stored in maps are visited on a copy that is written back afterwards.
+func visitHooks(cfg any, visit func(target any, path string)) {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+	visitStructHooks(value.Elem(), "", visit)
+}
+
+func visitStructHooks(structValue reflect.Value, path string, visit func(target any, path string)) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		visitValueHooks(structValue.Field(i), joinPath(path, fieldInfo.Name), visit)
+	}
+	visit(structValue.Addr().Interface(), path)
+}
+
+func visitValueHooks(value reflect.Value, path string, visit func(target any, path string)) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			visitValueHooks(value.Elem(), path, visit)
+		}
+	case reflect.Struct:
+		if isNestedStruct(value.Type()) {
+			visitStructHooks(value, path, visit)
+		}
+	case reflect.Slice, reflect.Array:
+		if !holdsNestedStructs(value.Type().Elem()) {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			visitValueHooks(value.Index(i), fmt.Sprintf("%s[%d]", path, i), visit)
+		}
+	case reflect.Map:
+		if !holdsNestedStructs(value.Type().Elem()) {
+			return
+		}
+		for _, key := range sortedMapKeys(value) {
+			element := reflect.New(value.Type().Elem()).Elem()
+			element.Set(value.MapIndex(key))
+			visitValueHooks(element, fmt.Sprintf("%s[%v]", path, key.Interface()), visit)
+			value.SetMapIndex(key, element)
+		}
+	}
+}
+
+// holdsNestedStructs reports whether values of type t, or the elements of
+// collections of type t, are nested structs.
+func holdsNestedStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return isNestedStruct(t)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return holdsNestedStructs(t.Elem())
+	default:
+		return false
+	}
+}
+
+// sortedMapKeys returns the keys of a map ordered by their printed form, so
+// that hooks and violations follow a stable order.
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+func applyDefaults(cfg any) {
+	visitHooks(cfg, func(target any, _ string) {
+		if defaulter, ok := target.(Defaulter); ok {
+			defaulter.SetDefaults()
+		}
+	})
+}
+
+func runFinalizers(cfg any) []error {
+	var collected []error
+	visitHooks(cfg, func(target any, path string) {
+		if finalizer, ok := target.(Finalizer); ok {
+			if err := finalizer.Finalize(); err != nil {
+				collected = append(collected, NewHookFailedError("Finalize", path, err))
+			}
+		}
+	})
+	return collected
+}
+
+func runValidators(cfg any) []error {
+	var collected []error
+	visitHooks(cfg, func(target any, path string) {
+		if validator, ok := target.(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				collected = append(collected, NewHookFailedError("Validate", path, err))
+			}
+		}
+	})
+	return collected
+}
+
+var defaulterType = reflect.TypeOf((*Defaulter)(nil)).Elem()
+
+// allocationTracker gives structs that a source allocates behind nil pointer
+// fields the defaults their Defaulter hooks would have set had the struct
+// existed before the sources ran. It records the struct pointers present
+// before one source runs and the paths that source assigns. Only fields that
+// are still zero and were not assigned take a default, so explicit zero
+// values and unexported fields survive.
+type allocationTracker struct {
+	known    map[any]bool
+	assigned map[string]bool
+}
+
+// newAllocationTracker returns nil when no type reachable from cfg
+// implements Defaulter, as there is nothing to fill in then.
+func newAllocationTracker(cfg any) *allocationTracker {
+	if !containsDefaulter(reflect.TypeOf(cfg), make(map[reflect.Type]bool)) {
+		return nil
+	}
+	tracker := &allocationTracker{known: make(map[any]bool), assigned: make(map[string]bool)}
+	visitHooks(cfg, func(target any, _ string) {
+		tracker.known[target] = true
+	})
+	return tracker
+}
+
+func (tracker *allocationTracker) ObserveField(event FieldEvent) {
+	if event.Assigned {
+		tracker.assigned[event.Path] = true
+	}
+}
+
+// applyDefaults fills defaults into every struct the source allocated.
+func (tracker *allocationTracker) applyDefaults(cfg any) {
+	if tracker == nil {
+		return
+	}
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return
+	}
+	tracker.visit(value.Elem(), "")
+}
+
+func (tracker *allocationTracker) visit(structValue reflect.Value, path string) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		fieldValue := structValue.Field(i)
+		if fieldInfo.PkgPath != "" || !isNestedStruct(fieldValue.Type()) {
+			continue
+		}
+		fieldPath := joinPath(path, fieldInfo.Name)
+		if fieldValue.Kind() == reflect.Struct {
+			tracker.visit(fieldValue, fieldPath)
+			continue
+		}
+		if fieldValue.IsNil() {
+			continue
+		}
+		if tracker.known[fieldValue.Interface()] {
+			tracker.visit(fieldValue.Elem(), fieldPath)
+			continue
+		}
+		tracker.fillAllocated(fieldValue, fieldPath)
+	}
+}
+
+// fillAllocated runs Defaulter hooks on a scratch struct of the type loaded
+// points to and copies the results into the zero, unassigned fields of the
+// struct itself.
+func (tracker *allocationTracker) fillAllocated(loaded reflect.Value, path string) {
+	if !containsDefaulter(loaded.Type(), make(map[reflect.Type]bool)) {
+		return
+	}
+	defaulted := reflect.New(loaded.Type().Elem())
+	applyDefaults(defaulted.Interface())
+	tracker.fill(loaded.Elem(), defaulted.Elem(), path)
+}
+
+func (tracker *allocationTracker) fill(loaded reflect.Value, defaulted reflect.Value, path string) {
+	structType := loaded.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		fieldPath := joinPath(path, fieldInfo.Name)
+		target := loaded.Field(i)
+		source := defaulted.Field(i)
+		switch {
+		case tracker.assigned[fieldPath]:
+		case !isNestedStruct(target.Type()):
+			if target.IsZero() {
+				target.Set(source)
+			}
+		case target.Kind() == reflect.Struct:
+			tracker.fill(target, source, fieldPath)
+		case target.IsNil():
+			target.Set(source)
+		case source.IsNil():
+			tracker.fillAllocated(target, fieldPath)
+		default:
+			tracker.fill(target.Elem(), source.Elem(), fieldPath)
+		}
+	}
+}
+
+// containsDefaulter reports whether t, or a nested struct reachable from it
+// through exported fields, implements Defaulter.
+func containsDefaulter(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if !isNestedStruct(t) {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if reflect.PointerTo(t).Implements(defaulterType) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.PkgPath == "" && containsDefaulter(field.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNestedStruct reports whether t is a struct, or a pointer to one, whose
+// fields are configuration fields rather than a value decoded from text.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !t.Implements(textUnmarshalerType) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func joinPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,207 @@
+package setup_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+)
+
+type HookedDatabase struct {
+	DSN  string
+	Host string `env:"HOST"`
+	Port int    `env:"PORT"`
+}
+
+func (database *HookedDatabase) SetDefaults() {
+	database.Host = "localhost"
+	database.Port = 5432
+}
+
+func (database *HookedDatabase) Finalize() error {
+	database.DSN = fmt.Sprintf("%s:%d", database.Host, database.Port)
+	return nil
+}
+
+func (database *HookedDatabase) Validate() error {
+	if database.Port == 0 {
+		return errors.New("port must be set")
+	}
+	return nil
+}
+
+type HookedConfiguration struct {
+	Database *HookedDatabase `envSegment:"db"`
+	Mode     string          `env:"MODE"`
+	Calls    []string
+}
+
+func (configuration *HookedConfiguration) SetDefaults() {
+	configuration.Mode = "dev"
+	configuration.Calls = append(configuration.Calls, "SetDefaults")
+}
+
+func (configuration *HookedConfiguration) Finalize() error {
+	configuration.Calls = append(configuration.Calls, "Finalize:"+configuration.Database.DSN)
+	if configuration.Mode == "broken" {
+		return errors.New("cannot finalize")
+	}
+	return nil
+}
+
+func (configuration HookedConfiguration) Validate() error {
+	if configuration.Mode != "dev" && configuration.Mode != "prod" {
+		return fmt.Errorf("unknown mode %q", configuration.Mode)
+	}
+	return nil
+}
+
+func TestLoader_Hooks_RunAroundSourcesWithNestedPaths(t *testing.T) {
+	loader := pkg.NewLoader(env.NewSource("app", ",", pkg.ModeFillMissing))
+	configuration := &HookedConfiguration{Database: &HookedDatabase{}}
+	t.Setenv("APP_DB_HOST", "db")
+	require.NoError(t, loader.Load(configuration))
+	assert.Equal(t, "dev", configuration.Mode)
+	assert.Equal(t, "localhost", configuration.Database.Host)
+	assert.Equal(t, "localhost:5432", configuration.Database.DSN)
+	assert.Equal(t, []string{"SetDefaults", "Finalize:localhost:5432"}, configuration.Calls)
+
+	loader = pkg.NewLoader(env.NewSource("app", ",", pkg.ModeOverride))
+	configuration = &HookedConfiguration{Database: &HookedDatabase{}}
+	t.Setenv("APP_DB_PORT", "0")
+	t.Setenv("APP_MODE", "broken")
+	loadError := loader.Load(configuration)
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrHookFailed))
+	var hookError *pkg.HookFailedError
+	require.True(t, errors.As(loadError, &hookError))
+	assert.Equal(t, "Finalize", hookError.Hook)
+	assert.Equal(t, "", hookError.Path)
+	message := loadError.Error()
+	assert.Contains(t, message, "hook failed: Finalize: cannot finalize")
+	assert.Contains(t, message, "hook failed: field Database: Validate: port must be set")
+	assert.Contains(t, message, "hook failed: Validate: unknown mode \"broken\"")
+	assert.Equal(t, "db:0", configuration.Database.DSN)
+
+	validationError := pkg.Validate(&HookedConfiguration{Mode: "x"})
+	require.Error(t, validationError)
+	assert.Contains(t, validationError.Error(), "Validate: unknown mode \"x\"")
+	assert.NotContains(t, validationError.Error(), "Database")
+}
+
+func TestLoader_Hooks_DefaultsStructsAllocatedBySources(t *testing.T) {
+	t.Setenv("APP_DB_HOST", "db")
+	loader := pkg.NewLoader(env.NewSource("app", ",", pkg.ModeOverride))
+	configuration := &HookedConfiguration{}
+	require.NoError(t, loader.Load(configuration))
+	require.NotNil(t, configuration.Database)
+	assert.Equal(t, "db", configuration.Database.Host)
+	assert.Equal(t, 5432, configuration.Database.Port)
+	assert.Equal(t, "db:5432", configuration.Database.DSN)
+
+	t.Setenv("APP_DB_PORT", "0")
+	configuration = &HookedConfiguration{}
+	loadError := loader.Load(configuration)
+	require.Error(t, loadError)
+	assert.Contains(t, loadError.Error(), "field Database: Validate: port must be set")
+	assert.Equal(t, 0, configuration.Database.Port)
+
+	plainSource := sourceFunc(func(target any) error {
+		target.(*HookedConfiguration).Database = &HookedDatabase{Port: 6432}
+		return nil
+	})
+	configuration = &HookedConfiguration{}
+	require.NoError(t, pkg.NewLoader(plainSource).Load(configuration))
+	assert.Equal(t, "", configuration.Database.Host)
+	assert.Equal(t, 6432, configuration.Database.Port)
+	assert.Equal(t, ":6432", configuration.Database.DSN)
+}
+
+type observedSourceFunc struct {
+	observer pkg.FieldObserver
+	load     func(target any)
+	assigned []string
+}
+
+func (source observedSourceFunc) Load(target any) error {
+	source.load(target)
+	for _, path := range source.assigned {
+		if source.observer != nil {
+			source.observer.ObserveField(pkg.FieldEvent{Path: path, Found: true, Eligible: true, Assigned: true})
+		}
+	}
+	return nil
+}
+
+func (source observedSourceFunc) WithFieldObserver(observer pkg.FieldObserver) pkg.Source {
+	source.observer = observer
+	return source
+}
+
+type AllocatedInner struct {
+	C      string
+	A      int
+	hidden int
+	B      bool
+}
+
+func (inner *AllocatedInner) SetDefaults() {
+	inner.A = 7
+	inner.B = true
+	inner.C = "c"
+}
+
+type AllocatedNoDefaults struct {
+	X int
+	y int
+}
+
+type AllocatedConfiguration struct {
+	Inner  *AllocatedInner
+	Plain  *AllocatedNoDefaults
+	Nested struct {
+		Inner *AllocatedInner
+	}
+}
+
+func TestLoader_Hooks_AllocatedStructsKeepExplicitZerosAndUnexportedFields(t *testing.T) {
+	t.Parallel()
+	source := observedSourceFunc{
+		load: func(target any) {
+			configuration := target.(*AllocatedConfiguration)
+			configuration.Inner = &AllocatedInner{A: 0, B: false, hidden: 99}
+			configuration.Plain = &AllocatedNoDefaults{X: 1, y: 99}
+			configuration.Nested.Inner = &AllocatedInner{C: "set"}
+		},
+		assigned: []string{"Inner.A", "Inner.B", "Plain.X", "Nested.Inner.C"},
+	}
+	configuration := &AllocatedConfiguration{}
+	require.NoError(t, pkg.NewLoader(source).Load(configuration))
+	assert.Equal(t, AllocatedInner{A: 0, B: false, C: "c", hidden: 99}, *configuration.Inner)
+	assert.Equal(t, AllocatedNoDefaults{X: 1, y: 99}, *configuration.Plain)
+	assert.Equal(t, AllocatedInner{A: 7, B: true, C: "set"}, *configuration.Nested.Inner)
+
+	plainSource := sourceFunc(func(target any) error {
+		target.(*AllocatedConfiguration).Inner = &AllocatedInner{A: 0, B: false}
+		target.(*AllocatedConfiguration).Plain = &AllocatedNoDefaults{X: 1, y: 99}
+		return nil
+	})
+	configuration = &AllocatedConfiguration{}
+	require.NoError(t, pkg.NewLoader(plainSource).Load(configuration))
+	assert.Equal(t, AllocatedInner{}, *configuration.Inner)
+	assert.Equal(t, AllocatedNoDefaults{X: 1, y: 99}, *configuration.Plain)
+
+	noDefaults := struct{ Plain *AllocatedNoDefaults }{}
+	source = observedSourceFunc{
+		load: func(target any) {
+			target.(*struct{ Plain *AllocatedNoDefaults }).Plain = &AllocatedNoDefaults{y: 99}
+		},
+	}
+	require.NoError(t, pkg.NewLoader(source).Load(&noDefaults))
+	assert.Equal(t, AllocatedNoDefaults{y: 99}, *noDefaults.Plain)
+}
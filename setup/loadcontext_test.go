@@ -0,0 +1,81 @@
+package setup_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+)
+
+type blockingContextSource struct {
+	loaded *atomic.Bool
+}
+
+func (source blockingContextSource) Load(target any) error {
+	return source.LoadContext(context.Background(), target)
+}
+
+func (source blockingContextSource) LoadContext(ctx context.Context, _ any) error {
+	source.loaded.Store(true)
+	<-ctx.Done()
+	return fmt.Errorf("waiting for remote: %w", ctx.Err())
+}
+
+func TestLoader_LoadContext_ReportsInterruptedSource(t *testing.T) {
+	type ContextConfiguration struct {
+		Name string `env:"NAME" validate:"required"`
+	}
+	t.Setenv("APP_NAME", "service")
+	var blockingLoaded, laterLoaded atomic.Bool
+	later := sourceFunc(func(any) error {
+		laterLoaded.Store(true)
+		return nil
+	})
+	loader := pkg.NewLoader(env.NewSource("app", ",", pkg.ModeOverride), blockingContextSource{loaded: &blockingLoaded}, later)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	configuration := &ContextConfiguration{}
+	loadError := loader.LoadContext(ctx, configuration)
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrLoadAggregatedFailed))
+	assert.True(t, errors.Is(loadError, context.DeadlineExceeded))
+	var sourceError *pkg.LoaderSourceFailedError
+	require.True(t, errors.As(loadError, &sourceError))
+	assert.True(t, sourceError.Interrupted)
+	assert.Equal(t, 1, sourceError.SourceIndex)
+	assert.Contains(t, sourceError.SourceName, "blockingContextSource")
+	assert.Contains(t, loadError.Error(), "index 1 named setup_test.blockingContextSource interrupted")
+	assert.True(t, blockingLoaded.Load())
+	assert.False(t, laterLoaded.Load())
+	assert.Equal(t, "service", configuration.Name)
+}
+
+func TestLoader_LoadContext_StopsBeforeSourceWhenCancelled(t *testing.T) {
+	var laterLoaded atomic.Bool
+	loader := pkg.NewLoader(sourceFunc(func(any) error {
+		laterLoaded.Store(true)
+		return nil
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	loadError := loader.LoadContext(ctx, &struct{ Name string }{})
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, context.Canceled))
+	var sourceError *pkg.LoaderSourceFailedError
+	require.True(t, errors.As(loadError, &sourceError))
+	assert.True(t, sourceError.Interrupted)
+	assert.Equal(t, 0, sourceError.SourceIndex)
+	assert.False(t, laterLoaded.Load())
+
+	require.NoError(t, loader.LoadContext(context.Background(), &struct{ Name string }{}))
+	assert.True(t, laterLoaded.Load())
+}
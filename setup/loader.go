@@ -1,8 +1,10 @@
 package setup
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 type LoadMode int
@@ -16,6 +18,14 @@ type Source interface {
 	Load(target any) error
 }
 
+// ContextSource is a Source that can stop early when its context is done,
+// for example a remote source bounded by a deadline. Loader.LoadContext
+// passes its context to LoadContext; Load passes context.Background().
+type ContextSource interface {
+	Source
+	LoadContext(ctx context.Context, target any) error
+}
+
 type Loader struct {
 	sources []Source
 }
@@ -25,13 +35,77 @@ func NewLoader(sources ...Source) *Loader {
 }
 
 func (l *Loader) Load(cfg any) error {
+	return l.load(context.Background(), cfg, nil)
+}
+
+// LoadContext loads cfg like Load, giving ContextSource sources ctx. Other
+// sources run unchanged. ctx is checked before every source; once it is done
+// no further sources run, and the interrupted source is reported as a
+// LoaderSourceFailedError with Interrupted set. Hooks, required fields and
+// validate tags are not checked after an interruption, because cfg is only
+// partially loaded.
+func (l *Loader) LoadContext(ctx context.Context, cfg any) error {
+	return l.load(ctx, cfg, nil)
+}
+
+// LoadWithProvenance loads cfg like Load and reports, for every leaf field,
+// which source assigned it last. Sources that do not implement
+// ObservableSource still load, but their assignments are not recorded.
+func (l *Loader) LoadWithProvenance(cfg any) (Provenance, error) {
+	recorder := newProvenanceRecorder(cfg)
+	err := l.load(context.Background(), cfg, recorder.observer)
+	return recorder.provenance, err
+}
+
+// load applies Defaulter hooks and runs every source in order, stopping with
+// an interrupted source error as soon as ctx is done. Structs a source
+// allocates get their Defaulter hooks right after that source. It then runs
+// Finalizer hooks, reports required fields no source supplied, checks
+// validate tags and runs Validator hooks. When observerFor is set, observable
+// sources are given the observer it returns for their index and type.
+func (l *Loader) load(ctx context.Context, cfg any, observerFor func(sourceIndex int, sourceType string) FieldObserver) error {
+	applyDefaults(cfg)
+	required := newRequiredTracker(cfg)
 	var collectedErrors []error
 	for index, source := range l.sources {
-		if loadError := source.Load(cfg); loadError != nil {
-			wrappedError := NewLoaderSourceFailedError(index, fmt.Sprintf("%T", source), loadError)
+		sourceType := fmt.Sprintf("%T", source)
+		if ctx.Err() != nil {
+			collectedErrors = append(collectedErrors, NewLoaderSourceInterruptedError(index, sourceType, ctx.Err()))
+			return NewAggregatedLoadFailedError(errors.Join(collectedErrors...))
+		}
+		var before []reflect.Value
+		var allocations *allocationTracker
+		if observable, ok := source.(ObservableSource); ok {
+			var observers fieldObservers
+			if allocations = newAllocationTracker(cfg); allocations != nil {
+				observers = append(observers, allocations)
+			}
+			if observerFor != nil {
+				observers = append(observers, observerFor(index, sourceType))
+			}
+			if required != nil {
+				observers = append(observers, required)
+			}
+			source = observable.WithFieldObserver(observers)
+		} else {
+			before = required.snapshot(cfg)
+		}
+		interrupted, loadError := loadSource(ctx, source, cfg)
+		required.creditChanged(cfg, before)
+		allocations.applyDefaults(cfg)
+		if interrupted {
+			collectedErrors = append(collectedErrors, NewLoaderSourceInterruptedError(index, sourceType, loadError))
+			return NewAggregatedLoadFailedError(errors.Join(collectedErrors...))
+		}
+		if loadError != nil {
+			wrappedError := NewLoaderSourceFailedError(index, sourceType, loadError)
 			collectedErrors = append(collectedErrors, wrappedError)
 		}
 	}
+	collectedErrors = append(collectedErrors, runFinalizers(cfg)...)
+	collectedErrors = append(collectedErrors, required.errors(cfg)...)
+	collectedErrors = append(collectedErrors, validateTarget(cfg)...)
+	collectedErrors = append(collectedErrors, runValidators(cfg)...)
 
 	if len(collectedErrors) > 0 {
 		aggregatedError := errors.Join(collectedErrors...)
@@ -40,3 +114,14 @@ func (l *Loader) Load(cfg any) error {
 
 	return nil
 }
+
+// loadSource runs one source, through LoadContext when it is a ContextSource.
+// It reports interrupted when a ContextSource failed after ctx was done.
+func loadSource(ctx context.Context, source Source, cfg any) (bool, error) {
+	contextSource, ok := source.(ContextSource)
+	if !ok {
+		return false, source.Load(cfg)
+	}
+	loadError := contextSource.LoadContext(ctx, cfg)
+	return loadError != nil && ctx.Err() != nil, loadError
+}
@@ -115,3 +115,9 @@ func TestLoader_AggregatesErrors_FromRealSources(t *testing.T) {
 	assert.Contains(t, errorMessage, "dict.Source")
 	assert.Contains(t, errorMessage, "flags.Source")
 }
+
+type sourceFunc func(target any) error
+
+func (function sourceFunc) Load(target any) error {
+	return function(target)
+}
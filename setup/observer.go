@@ -0,0 +1,41 @@
+package setup
+
+// FieldEvent reports how a source handled one leaf field during Load.
+type FieldEvent struct {
+	// Err is the conversion or assignment error, if any.
+	Err error
+	// Path is the field path as built by sourceutil.MakePath, e.g. "Database.Port".
+	Path string
+	// Key is what the source looked up: an env var name, a flag name, a JSON path.
+	Key string
+	// Found reports whether the source had a value under Key.
+	Found bool
+	// Eligible reports whether the load mode let the source write the field,
+	// as decided by sourceutil.ShouldAssign.
+	Eligible bool
+	// Default reports whether the assigned value came from a default tag.
+	Default bool
+	// Assigned reports whether the field was written.
+	Assigned bool
+}
+
+// FieldObserver receives an event for every leaf field a source examines,
+// whether or not it assigns the field.
+type FieldObserver interface {
+	ObserveField(event FieldEvent)
+}
+
+// FieldObserverFunc adapts a function to FieldObserver.
+type FieldObserverFunc func(event FieldEvent)
+
+func (observerFunc FieldObserverFunc) ObserveField(event FieldEvent) {
+	observerFunc(event)
+}
+
+// ObservableSource is implemented by sources that can report field events.
+// WithFieldObserver returns a copy of the source that sends every event to
+// observer and leaves the original untouched.
+type ObservableSource interface {
+	Source
+	WithFieldObserver(observer FieldObserver) Source
+}
@@ -0,0 +1,150 @@
+package setup
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// FieldProvenance records which source last assigned a field.
+type FieldProvenance struct {
+	// Path is the field path, e.g. "Database.Port".
+	Path string
+	// SourceType is the Go type of the source, e.g. "*env.Source".
+	SourceType string
+	// Key is the raw key the value was read from: env var, flag name, JSON path.
+	Key string
+	// SourceIndex is the position of the source in the loader, or -1.
+	SourceIndex int
+	// Default reports whether the value came from a default tag.
+	Default bool
+	// Touched is false when no source assigned the field.
+	Touched bool
+}
+
+// Provenance maps field paths to the source that last assigned them. Fields
+// are listed in declaration order.
+type Provenance struct {
+	index  map[string]int
+	fields []FieldProvenance
+}
+
+// Fields returns every leaf field of the target, touched or not.
+func (provenance Provenance) Fields() []FieldProvenance {
+	return provenance.fields
+}
+
+// Field returns the provenance of the field at path.
+func (provenance Provenance) Field(path string) (FieldProvenance, bool) {
+	position, ok := provenance.index[path]
+	if !ok {
+		return FieldProvenance{}, false
+	}
+	return provenance.fields[position], true
+}
+
+// String renders one line per field, for logs and debugging endpoints.
+func (provenance Provenance) String() string {
+	var builder strings.Builder
+	for _, field := range provenance.fields {
+		builder.WriteString(field.Path)
+		builder.WriteString(": ")
+		if !field.Touched {
+			builder.WriteString("untouched\n")
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("source %d (%s)", field.SourceIndex, field.SourceType))
+		if field.Key != "" {
+			builder.WriteString(" key " + field.Key)
+		}
+		if field.Default {
+			builder.WriteString(" default")
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// provenanceRecorder collects the assigned events of every source in order.
+type provenanceRecorder struct {
+	provenance Provenance
+}
+
+func newProvenanceRecorder(cfg any) *provenanceRecorder {
+	recorder := &provenanceRecorder{provenance: Provenance{index: make(map[string]int)}}
+	walkLeafPaths(cfg, func(path string, _ reflect.StructField) {
+		recorder.field(path)
+	})
+	return recorder
+}
+
+// walkLeafPaths calls visit with the path and declaration of every exported
+// leaf field of the struct cfg points to, in declaration order. Nested structs
+// are descended into unless they implement encoding.TextUnmarshaler. A struct
+// field whose type is already being walked, as in recursive types such as
+// type node struct{ Child *node }, is visited as a leaf instead.
+func walkLeafPaths(cfg any, visit func(path string, fieldInfo reflect.StructField)) {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		walkStructLeafPaths(t, "", map[reflect.Type]bool{t: true}, visit)
+	}
+}
+
+func walkStructLeafPaths(structType reflect.Type, prefix string, walking map[reflect.Type]bool, visit func(path string, fieldInfo reflect.StructField)) {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		path := fieldInfo.Name
+		if prefix != "" {
+			path = prefix + "." + fieldInfo.Name
+		}
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct && !walking[t] && !t.Implements(textUnmarshalerType) && !reflect.PointerTo(t).Implements(textUnmarshalerType) {
+			walking[t] = true
+			walkStructLeafPaths(t, path, walking, visit)
+			delete(walking, t)
+			continue
+		}
+		visit(path, fieldInfo)
+	}
+}
+
+// field returns the entry for path, adding it when a source reports a path
+// the type walk did not produce, such as a struct assigned as a whole.
+func (recorder *provenanceRecorder) field(path string) *FieldProvenance {
+	position, ok := recorder.provenance.index[path]
+	if !ok {
+		position = len(recorder.provenance.fields)
+		recorder.provenance.index[path] = position
+		recorder.provenance.fields = append(recorder.provenance.fields, FieldProvenance{Path: path, SourceIndex: -1})
+	}
+	return &recorder.provenance.fields[position]
+}
+
+func (recorder *provenanceRecorder) observer(sourceIndex int, sourceType string) FieldObserver {
+	return FieldObserverFunc(func(event FieldEvent) {
+		if !event.Assigned {
+			recorder.field(event.Path)
+			return
+		}
+		*recorder.field(event.Path) = FieldProvenance{
+			Path:        event.Path,
+			SourceType:  sourceType,
+			Key:         event.Key,
+			SourceIndex: sourceIndex,
+			Default:     event.Default,
+			Touched:     true,
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package setup_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/dict"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	"github.com/Sufir/go-set-me-up/setup/source/flags"
+	jsonfile "github.com/Sufir/go-set-me-up/setup/source/json-file"
+)
+
+func TestLoader_LoadWithProvenance_RecordsLastAssigningSource(t *testing.T) {
+	jsonContent := []byte(`{"Name":"from-json","Port":8080,"Outer":{"Value":1}}`)
+	tempFilePath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(tempFilePath, jsonContent, 0o644))
+
+	type ProvenanceConfiguration struct {
+		Name   string `json:"Name" env:"NAME" flag:"name"`
+		Level  string `flag:"level" flagDefault:"info"`
+		Unused string `env:"UNUSED"`
+		Outer  struct {
+			Value int `json:"Value" env:"VALUE"`
+		} `json:"Outer" envSegment:"outer"`
+		Port    int `json:"Port" env:"PORT"`
+		Dict    int
+		Shadow  int `json:"Shadow"`
+		Verbose bool
+	}
+
+	t.Setenv("APP_OUTER_VALUE", "2")
+	loader := pkg.NewLoader(
+		jsonfile.NewSource(tempFilePath, pkg.ModeOverride),
+		dict.NewSource(map[string]any{"dict": 7}, pkg.ModeOverride),
+		env.NewSource("app", ",", pkg.ModeOverride),
+		flags.NewSourceWithArgs(pkg.ModeOverride, []string{"--name", "from-flag"}),
+	)
+	configuration := &ProvenanceConfiguration{}
+	provenance, loadError := loader.LoadWithProvenance(configuration)
+	require.NoError(t, loadError)
+	assert.Equal(t, "from-flag", configuration.Name)
+
+	paths := make([]string, 0, len(provenance.Fields()))
+	for _, field := range provenance.Fields() {
+		paths = append(paths, field.Path)
+	}
+	assert.Equal(t, []string{"Name", "Level", "Unused", "Outer.Value", "Port", "Dict", "Shadow", "Verbose"}, paths)
+
+	name, ok := provenance.Field("Name")
+	require.True(t, ok)
+	assert.Equal(t, pkg.FieldProvenance{Path: "Name", SourceType: "*flags.Source", Key: "--name", SourceIndex: 3, Touched: true}, name)
+
+	level, _ := provenance.Field("Level")
+	assert.Equal(t, 3, level.SourceIndex)
+	assert.True(t, level.Default)
+
+	outer, _ := provenance.Field("Outer.Value")
+	assert.Equal(t, "*env.Source", outer.SourceType)
+	assert.Equal(t, "APP_OUTER_VALUE", outer.Key)
+
+	port, _ := provenance.Field("Port")
+	assert.Equal(t, pkg.FieldProvenance{Path: "Port", SourceType: "*jsonfile.Source", Key: "Port", SourceIndex: 0, Touched: true}, port)
+
+	dictField, _ := provenance.Field("Dict")
+	assert.Equal(t, "dict", dictField.Key)
+	assert.Equal(t, 1, dictField.SourceIndex)
+
+	for _, path := range []string{"Unused", "Shadow", "Verbose"} {
+		field, found := provenance.Field(path)
+		require.True(t, found, path)
+		assert.False(t, field.Touched, path)
+		assert.Equal(t, -1, field.SourceIndex, path)
+	}
+	_, found := provenance.Field("Missing")
+	assert.False(t, found)
+
+	report := provenance.String()
+	assert.Contains(t, report, "Name: source 3 (*flags.Source) key --name\n")
+	assert.Contains(t, report, "Level: source 3 (*flags.Source) key --level default\n")
+	assert.Contains(t, report, "Unused: untouched\n")
+}
+
+type recursiveNode struct {
+	Child *recursiveNode
+	Name  string `required:"true"`
+}
+
+func TestLoader_RecursiveTypes(t *testing.T) {
+	loader := pkg.NewLoader(dict.NewSource(map[string]any{"Name": "root"}, pkg.ModeOverride))
+	node := &recursiveNode{}
+	require.NoError(t, loader.Load(node))
+	assert.Equal(t, "root", node.Name)
+	assert.Nil(t, node.Child)
+
+	provenance, provenanceError := loader.LoadWithProvenance(&recursiveNode{})
+	require.NoError(t, provenanceError)
+	paths := make([]string, 0, len(provenance.Fields()))
+	for _, field := range provenance.Fields() {
+		paths = append(paths, field.Path)
+	}
+	assert.Equal(t, []string{"Child", "Name"}, paths)
+	name, ok := provenance.Field("Name")
+	require.True(t, ok)
+	assert.Equal(t, 0, name.SourceIndex)
+
+	explanation, explainError := loader.Explain(&recursiveNode{Child: &recursiveNode{Name: "leaf"}})
+	require.NoError(t, explainError)
+	nameTrace, ok := explanation.Field("Name")
+	require.True(t, ok)
+	winner, ok := nameTrace.Winner()
+	require.True(t, ok)
+	assert.Equal(t, "root", winner.Value)
+
+	loadError := pkg.NewLoader().Load(&recursiveNode{})
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrRequiredMissing))
+}
@@ -0,0 +1,78 @@
+package reference
+
+import (
+	"strings"
+)
+
+// Man renders the fields of cfg as a roff man page. Each field is a tagged
+// paragraph listing its description, environment variable, flags, JSON key and
+// default.
+func (generator Generator) Man(cfg any) (string, error) {
+	fields, err := generator.Fields(cfg)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	builder.WriteString(".TH " + roffEscape(strings.ToUpper(generator.program)) + " " + generator.section + " \"\" \"\" " + roffQuote(generator.program+" configuration") + "\n")
+	builder.WriteString(".SH NAME\n")
+	builder.WriteString(roffEscape(generator.program) + " \\- configuration reference\n")
+	if generator.description != "" {
+		builder.WriteString(".SH DESCRIPTION\n")
+		builder.WriteString(roffLine(generator.description) + "\n")
+	}
+	builder.WriteString(".SH OPTIONS\n")
+	for _, field := range fields {
+		builder.WriteString(".TP\n")
+		builder.WriteString(".B " + roffEscape(field.Path) + "\n")
+		var lines []string
+		if field.Description != "" {
+			lines = append(lines, roffLine(field.Description))
+		}
+		if field.EnvKey != "" {
+			lines = append(lines, "Environment: \\fB"+roffEscape(field.EnvKey)+"\\fR")
+		}
+		if flags := roffBoldList(field.Flag, field.FlagShort); flags != "" {
+			lines = append(lines, "Flag: "+flags)
+		}
+		if field.JSONKey != "" {
+			lines = append(lines, "JSON key: \\fB"+roffEscape(field.JSONKey)+"\\fR")
+		}
+		if value := field.Default(); value != "" {
+			lines = append(lines, "Default: "+roffEscape(value))
+		}
+		builder.WriteString(strings.Join(lines, "\n.br\n"))
+		if len(lines) > 0 {
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String(), nil
+}
+
+func roffBoldList(values ...string) string {
+	bold := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			bold = append(bold, "\\fB"+roffEscape(value)+"\\fR")
+		}
+	}
+	return strings.Join(bold, ", ")
+}
+
+// roffEscape escapes backslashes and hyphens so that flags render as typed.
+func roffEscape(text string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`, "\n", " ").Replace(text)
+}
+
+// roffLine escapes text that starts a line, where a leading period or
+// apostrophe would be read as a request.
+func roffLine(text string) string {
+	escaped := roffEscape(text)
+	if strings.HasPrefix(escaped, ".") || strings.HasPrefix(escaped, "'") {
+		return `\&` + escaped
+	}
+	return escaped
+}
+
+func roffQuote(text string) string {
+	return `"` + strings.ReplaceAll(roffEscape(text), `"`, `""`) + `"`
+}
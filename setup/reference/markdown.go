@@ -0,0 +1,48 @@
+package reference
+
+import (
+	"strings"
+)
+
+// Markdown renders the fields of cfg as a Markdown document with one table row
+// per field.
+func (generator Generator) Markdown(cfg any) (string, error) {
+	fields, err := generator.Fields(cfg)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	builder.WriteString("# " + generator.program + " configuration\n\n")
+	if generator.description != "" {
+		builder.WriteString(generator.description + "\n\n")
+	}
+	builder.WriteString("| Field | Environment | Flag | JSON key | Default | Description |\n")
+	builder.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, field := range fields {
+		cells := []string{
+			markdownCode(field.Path),
+			markdownCode(field.EnvKey),
+			markdownCode(field.Flag, field.FlagShort),
+			markdownCode(field.JSONKey),
+			markdownCode(field.Default()),
+			markdownEscape(field.Description),
+		}
+		builder.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return builder.String(), nil
+}
+
+// markdownCode formats the non-empty values as code spans separated by commas.
+func markdownCode(values ...string) string {
+	spans := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			spans = append(spans, "`"+strings.ReplaceAll(value, "|", `\|`)+"`")
+		}
+	}
+	return strings.Join(spans, ", ")
+}
+
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
@@ -0,0 +1,224 @@
+// Package reference generates configuration reference documents from the
+// tags of a configuration struct: a Markdown table and a roff man page that
+// list, for every field, the environment variable, command-line flags, JSON
+// key, default and description.
+package reference
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+// Field describes every way to set one leaf field. Empty strings mean that the
+// corresponding source does not read the field.
+type Field struct {
+	Path        string
+	EnvKey      string
+	Flag        string
+	FlagShort   string
+	JSONKey     string
+	EnvDefault  string
+	FlagDefault string
+	Description string
+}
+
+// Default returns the default shown in documents. When the env and flags
+// defaults differ, both are returned, labelled with their source.
+func (field Field) Default() string {
+	switch {
+	case field.EnvDefault == "" || field.EnvDefault == field.FlagDefault:
+		return field.FlagDefault
+	case field.FlagDefault == "":
+		return field.EnvDefault
+	default:
+		return "env: " + field.EnvDefault + ", flags: " + field.FlagDefault
+	}
+}
+
+// Generator renders reference documents for one program.
+type Generator struct {
+	program     string
+	description string
+	envPrefix   string
+	separator   string
+	section     string
+}
+
+// Option configures a Generator created by New.
+type Option func(*Generator)
+
+// WithEnvPrefix sets the prefix passed to env.NewSource, so that documented
+// keys match the variables the program actually reads.
+func WithEnvPrefix(prefix string) Option {
+	return func(generator *Generator) {
+		generator.envPrefix = prefix
+	}
+}
+
+// WithSegmentSeparator sets the flagSegment separator configured on the flags
+// source. The default is ".".
+func WithSegmentSeparator(separator string) Option {
+	return func(generator *Generator) {
+		generator.separator = separator
+	}
+}
+
+// WithDescription sets the introductory paragraph of both documents.
+func WithDescription(description string) Option {
+	return func(generator *Generator) {
+		generator.description = description
+	}
+}
+
+// WithSection sets the man page section. The default is "5".
+func WithSection(section string) Option {
+	return func(generator *Generator) {
+		if section != "" {
+			generator.section = section
+		}
+	}
+}
+
+func New(program string, options ...Option) *Generator {
+	generator := &Generator{program: program, separator: ".", section: "5"}
+	for _, option := range options {
+		if option != nil {
+			option(generator)
+		}
+	}
+	return generator
+}
+
+// walkState carries the names accumulated on the way down to a nested struct.
+// A disabled source stops contributing to the fields below.
+type walkState struct {
+	path        string
+	flagPrefix  string
+	jsonPrefix  string
+	envSegments []string
+	noEnv       bool
+	noJSON      bool
+}
+
+// Fields lists the leaf fields of cfg in declaration order.
+func (generator Generator) Fields(cfg any) ([]Field, error) {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, setup.NewInvalidTargetError("target must be a struct or pointer to struct")
+	}
+	state := walkState{}
+	if generator.envPrefix != "" {
+		state.envSegments = []string{sourceutil.ConvertToEnvVar(generator.envPrefix)}
+	}
+	return generator.collect(t, state, nil), nil
+}
+
+func (generator Generator) collect(structType reflect.Type, state walkState, fields []Field) []Field {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		tagEnv := fieldInfo.Tag.Get("env")
+		tagFlag := fieldInfo.Tag.Get("flag")
+		jsonName := jsonTagName(fieldInfo.Tag.Get("json"))
+		path := sourceutil.MakePath(state.path, fieldInfo.Name)
+
+		if nested, ok := nestedStruct(fieldInfo); ok {
+			next := walkState{
+				path:        path,
+				flagPrefix:  generator.nestedFlagPrefix(state.flagPrefix, fieldInfo),
+				envSegments: state.envSegments,
+				noEnv:       state.noEnv || tagEnv == "-",
+				noJSON:      state.noJSON || jsonName == "",
+			}
+			if !next.noEnv {
+				segment := fieldInfo.Tag.Get("envSegment")
+				if segment == "" {
+					segment = fieldInfo.Name
+				}
+				if segment = sourceutil.ConvertToEnvVar(segment); segment != "" {
+					next.envSegments = append(append([]string(nil), state.envSegments...), segment)
+				}
+			}
+			if !next.noJSON {
+				next.jsonPrefix = sourceutil.MakePath(state.jsonPrefix, jsonName)
+			}
+			fields = generator.collect(nested, next, fields)
+			continue
+		}
+
+		field := Field{Path: path, Description: description(fieldInfo)}
+		if !state.noEnv && tagEnv != "" && tagEnv != "-" {
+			field.EnvKey = strings.Join(append(append([]string(nil), state.envSegments...), sourceutil.ConvertToEnvVar(tagEnv)), "_")
+			field.EnvDefault = fieldInfo.Tag.Get("envDefault")
+		}
+		if tagFlag != "" && tagFlag != "-" {
+			field.Flag = "--" + state.flagPrefix + tagFlag
+			if short := fieldInfo.Tag.Get("flagShort"); short != "" {
+				field.FlagShort = "-" + short
+			}
+			field.FlagDefault = fieldInfo.Tag.Get("flagDefault")
+		}
+		if !state.noJSON && jsonName != "" {
+			field.JSONKey = sourceutil.MakePath(state.jsonPrefix, jsonName)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// nestedStruct reports whether the sources descend into the field instead of
+// assigning it as a whole, and returns the struct type to descend into.
+func nestedStruct(fieldInfo reflect.StructField) (reflect.Type, bool) {
+	tagEnv := fieldInfo.Tag.Get("env")
+	tagFlag := fieldInfo.Tag.Get("flag")
+	if (tagEnv != "" && tagEnv != "-") || (tagFlag != "" && tagFlag != "-") {
+		return nil, false
+	}
+	t := fieldInfo.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || sourceutil.IsTextUnmarshaler(t) {
+		return nil, false
+	}
+	return t, true
+}
+
+// nestedFlagPrefix mirrors the flags source: flagSegment extends the long-name
+// prefix, and a command field starts a fresh one.
+func (generator Generator) nestedFlagPrefix(prefix string, fieldInfo reflect.StructField) string {
+	if tagCmd := fieldInfo.Tag.Get("cmd"); tagCmd != "" && tagCmd != "-" {
+		return ""
+	}
+	segment := fieldInfo.Tag.Get("flagSegment")
+	if segment == "" {
+		return prefix
+	}
+	return prefix + segment + generator.separator
+}
+
+func jsonTagName(tag string) string {
+	if tag == "" || tag == "-" {
+		return ""
+	}
+	if index := strings.IndexByte(tag, ','); index >= 0 {
+		return tag[:index]
+	}
+	return tag
+}
+
+// description prefers the desc tag and falls back to flagUsage.
+func description(fieldInfo reflect.StructField) string {
+	if desc := fieldInfo.Tag.Get("desc"); desc != "" {
+		return desc
+	}
+	return fieldInfo.Tag.Get("flagUsage")
+}
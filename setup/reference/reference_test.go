@@ -0,0 +1,118 @@
+package reference
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	"github.com/Sufir/go-set-me-up/setup/source/flags"
+)
+
+type ReferenceDatabase struct {
+	Host string `env:"HOST" flag:"host" flagShort:"H" json:"host" envDefault:"localhost" flagDefault:"localhost" desc:"Database host"`
+	Port int    `env:"PORT" flag:"port" json:"port,omitempty" envDefault:"5432" flagDefault:"5433"`
+}
+
+type ReferenceConfig struct {
+	Started  time.Time          `json:"started"`
+	Database *ReferenceDatabase `json:"database" envSegment:"db" flagSegment:"db"`
+	Internal struct {
+		Token string `env:"TOKEN" flag:"token" json:"token"`
+	} `env:"-"`
+	Name    string   `env:"NAME" flag:"name" flagShort:"n" json:"name" flagUsage:"Service name"`
+	Tags    []string `env:"TAGS" json:"tags" desc:"Tags | labels"`
+	Verbose bool     `flag:"verbose"`
+}
+
+func TestGenerator_Fields(t *testing.T) {
+	t.Parallel()
+	fields, err := New("svc", WithEnvPrefix("app")).Fields(&ReferenceConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, []Field{
+		{Path: "Started", JSONKey: "started"},
+		{Path: "Database.Host", EnvKey: "APP_DB_HOST", Flag: "--db.host", FlagShort: "-H", JSONKey: "database.host", EnvDefault: "localhost", FlagDefault: "localhost", Description: "Database host"},
+		{Path: "Database.Port", EnvKey: "APP_DB_PORT", Flag: "--db.port", JSONKey: "database.port", EnvDefault: "5432", FlagDefault: "5433"},
+		{Path: "Internal.Token", Flag: "--token"},
+		{Path: "Name", EnvKey: "APP_NAME", Flag: "--name", FlagShort: "-n", JSONKey: "name", Description: "Service name"},
+		{Path: "Tags", EnvKey: "APP_TAGS", JSONKey: "tags", Description: "Tags | labels"},
+		{Path: "Verbose", Flag: "--verbose"},
+	}, fields)
+	assert.Equal(t, "localhost", fields[1].Default())
+	assert.Equal(t, "env: 5432, flags: 5433", fields[2].Default())
+
+	_, err = New("svc").Fields(42)
+	assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+}
+
+func TestGenerator_KeysMatchSources(t *testing.T) {
+	t.Parallel()
+	fields, err := New("svc", WithEnvPrefix("app"), WithSegmentSeparator("-")).Fields(&ReferenceConfig{})
+	require.NoError(t, err)
+	environment := map[string]string{}
+	var args []string
+	for _, field := range fields {
+		if field.EnvKey != "" && field.Path == "Database.Host" {
+			environment[field.EnvKey] = "env.local"
+		}
+		if field.Flag != "" && field.Path == "Database.Port" {
+			args = append(args, field.Flag, "7000")
+		}
+	}
+	cfg := &ReferenceConfig{}
+	require.NoError(t, env.NewSourceWithLookup("app", ",", setup.ModeOverride, env.MapLookup(environment)).Load(cfg))
+	require.NoError(t, flags.NewSourceWithOptions(setup.ModeOverride, flags.WithArgs(args), flags.WithSegmentSeparator("-")).Load(cfg))
+	assert.Equal(t, "env.local", cfg.Database.Host)
+	assert.Equal(t, 7000, cfg.Database.Port)
+}
+
+func TestGenerator_Markdown(t *testing.T) {
+	t.Parallel()
+	type Config struct {
+		Name string   `env:"NAME" flag:"name" flagShort:"n" json:"name" flagDefault:"svc" desc:"Service name"`
+		Tags []string `env:"TAGS" desc:"Tags | labels"`
+	}
+	document, err := New("svc", WithEnvPrefix("app"), WithDescription("Settings of the svc daemon.")).Markdown(&Config{})
+	require.NoError(t, err)
+	assert.Equal(t, "# svc configuration\n\n"+
+		"Settings of the svc daemon.\n\n"+
+		"| Field | Environment | Flag | JSON key | Default | Description |\n"+
+		"| --- | --- | --- | --- | --- | --- |\n"+
+		"| `Name` | `APP_NAME` | `--name`, `-n` | `name` | `svc` | Service name |\n"+
+		"| `Tags` | `APP_TAGS` |  |  |  | Tags \\| labels |\n", document)
+}
+
+func TestGenerator_Man(t *testing.T) {
+	t.Parallel()
+	type Config struct {
+		Name string `env:"NAME" flag:"name" flagShort:"n" json:"name" flagDefault:"svc" desc:".hidden \\ value"`
+		Port int    `flag:"http-port"`
+	}
+	page, err := New("my-svc", WithSection("1"), WithDescription("Settings.")).Man(&Config{})
+	require.NoError(t, err)
+	assert.Equal(t, `.TH MY\-SVC 1 "" "" "my\-svc configuration"
+.SH NAME
+my\-svc \- configuration reference
+.SH DESCRIPTION
+Settings.
+.SH OPTIONS
+.TP
+.B Name
+\&.hidden \e value
+.br
+Environment: \fBNAME\fR
+.br
+Flag: \fB\-\-name\fR, \fB\-n\fR
+.br
+JSON key: \fBname\fR
+.br
+Default: svc
+.TP
+.B Port
+Flag: \fB\-\-http\-port\fR
+`, page)
+}
@@ -0,0 +1,62 @@
+package reload
+
+import (
+	"reflect"
+)
+
+// ChangedPaths returns the dotted paths of the exported leaf fields that
+// differ between old and updated, in declaration order. Nested structs are
+// compared field by field; structs without exported fields, such as
+// time.Time, and all other values are compared as a whole. A nil pointer on
+// one side only is reported at the pointer's own path.
+func ChangedPaths[T any](old *T, updated *T) []string {
+	if old == nil || updated == nil {
+		if old == updated {
+			return nil
+		}
+		return []string{""}
+	}
+	var paths []string
+	diffValues(reflect.ValueOf(old).Elem(), reflect.ValueOf(updated).Elem(), "", &paths)
+	return paths
+}
+
+func diffValues(old reflect.Value, updated reflect.Value, path string, paths *[]string) {
+	if old.Kind() == reflect.Ptr {
+		if old.IsNil() || updated.IsNil() {
+			if old.IsNil() != updated.IsNil() {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		diffValues(old.Elem(), updated.Elem(), path, paths)
+		return
+	}
+	if old.Kind() != reflect.Struct || !hasExportedFields(old.Type()) {
+		if !reflect.DeepEqual(old.Interface(), updated.Interface()) {
+			*paths = append(*paths, path)
+		}
+		return
+	}
+	structType := old.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		fieldPath := fieldInfo.Name
+		if path != "" {
+			fieldPath = path + "." + fieldInfo.Name
+		}
+		diffValues(old.Field(i), updated.Field(i), fieldPath, paths)
+	}
+}
+
+func hasExportedFields(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
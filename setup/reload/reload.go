@@ -0,0 +1,196 @@
+// Package reload keeps a configuration value up to date for long-running
+// services. A Reloader re-runs every source of a setup.Loader into a fresh
+// value when a watched file changes or when Reload is called, and swaps the
+// new value in only if loading and validation succeeded. Subscribers are told
+// which field paths changed. OnSignal and LoadOnSignal reload when the
+// process receives a signal such as SIGHUP.
+package reload
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+// Change describes one successful reload that altered the configuration.
+// Old and New must be treated as read-only; other goroutines may hold them.
+type Change[T any] struct {
+	Old   *T
+	New   *T
+	Paths []string
+}
+
+// Option configures a Reloader created by New.
+type Option func(*options)
+
+type options struct {
+	onError  func(error)
+	files    []string
+	interval time.Duration
+}
+
+// WithFiles makes Watch poll the given files, typically the JSON, YAML, TOML
+// or dotenv files the loader reads. A file that appears, disappears or
+// changes size or modification time triggers a reload.
+func WithFiles(paths ...string) Option {
+	return func(o *options) {
+		o.files = append(o.files, paths...)
+	}
+}
+
+// WithInterval sets how often Watch polls the watched files. The default is
+// one second.
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		if interval > 0 {
+			o.interval = interval
+		}
+	}
+}
+
+// WithErrorHandler receives the errors of reloads started by Watch. Reload
+// returns its error to the caller directly.
+func WithErrorHandler(handler func(error)) Option {
+	return func(o *options) {
+		o.onError = handler
+	}
+}
+
+// Reloader holds the current configuration in a setup.Holder and tells
+// subscribers what changed on every reload.
+type Reloader[T any] struct {
+	holder      *setup.Holder[T]
+	subscribers map[int]func(Change[T])
+	baseline    string
+	options     options
+	reloadMu    sync.Mutex
+	mu          sync.Mutex
+	nextID      int
+}
+
+// New loads the initial configuration and returns a Reloader holding it. It
+// fails when the initial load fails.
+func New[T any](loader *setup.Loader, opts ...Option) (*Reloader[T], error) {
+	reloader := &Reloader[T]{
+		subscribers: make(map[int]func(Change[T])),
+		options:     options{interval: time.Second},
+	}
+	for _, opt := range opts {
+		opt(&reloader.options)
+	}
+	reloader.baseline = snapshot(reloader.options.files)
+	holder, err := setup.NewHolder[T](loader)
+	if err != nil {
+		return nil, err
+	}
+	reloader.holder = holder
+	return reloader, nil
+}
+
+// Current returns the configuration in effect. The value is shared and must
+// not be modified.
+func (reloader *Reloader[T]) Current() *T {
+	return reloader.holder.Get()
+}
+
+// Subscribe registers fn to be called after every reload that changed at
+// least one field. Calls happen on the goroutine that reloaded, one at a
+// time. The returned function removes the subscription.
+func (reloader *Reloader[T]) Subscribe(fn func(Change[T])) func() {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+	id := reloader.nextID
+	reloader.nextID++
+	reloader.subscribers[id] = fn
+	return func() {
+		reloader.mu.Lock()
+		defer reloader.mu.Unlock()
+		delete(reloader.subscribers, id)
+	}
+}
+
+// Reload reloads the holder, which publishes the fresh value only when
+// loading, including validation, succeeds; only then are subscribers
+// notified. On failure the current value is kept and the load error is
+// returned.
+func (reloader *Reloader[T]) Reload() error {
+	reloader.reloadMu.Lock()
+	defer reloader.reloadMu.Unlock()
+	old := reloader.holder.Get()
+	if err := reloader.holder.Reload(); err != nil {
+		return err
+	}
+	fresh := reloader.holder.Get()
+	paths := ChangedPaths(old, fresh)
+	if len(paths) == 0 {
+		return nil
+	}
+	change := Change[T]{Old: old, New: fresh, Paths: paths}
+	for _, subscriber := range reloader.subscribersInOrder() {
+		subscriber(change)
+	}
+	return nil
+}
+
+func (reloader *Reloader[T]) subscribersInOrder() []func(Change[T]) {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+	subscribers := make([]func(Change[T]), 0, len(reloader.subscribers))
+	for id := 0; id < reloader.nextID; id++ {
+		if subscriber, ok := reloader.subscribers[id]; ok {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	return subscribers
+}
+
+// Watch polls the files given with WithFiles and reloads when one of them
+// changes, until ctx is cancelled. It returns ctx.Err(). Changes made since
+// New read the files are picked up on the first poll. Reload errors are
+// passed to the WithErrorHandler handler, if any, and watching continues.
+// Watch must not be called more than once at a time.
+func (reloader *Reloader[T]) Watch(ctx context.Context) error {
+	ticker := time.NewTicker(reloader.options.interval)
+	defer ticker.Stop()
+	previous := reloader.baseline
+	defer func() {
+		reloader.baseline = previous
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		current := snapshot(reloader.options.files)
+		if current == previous {
+			continue
+		}
+		previous = current
+		if err := reloader.Reload(); err != nil && reloader.options.onError != nil {
+			reloader.options.onError(err)
+		}
+	}
+}
+
+// snapshot summarises the size and modification time of every file, so that
+// two snapshots differ when any file was written, created or removed.
+func snapshot(paths []string) string {
+	var summary []byte
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			summary = append(summary, "missing;"...)
+			continue
+		}
+		summary = append(summary, info.ModTime().Format(time.RFC3339Nano)...)
+		summary = append(summary, ' ')
+		summary = strconv.AppendInt(summary, info.Size(), 10)
+		summary = append(summary, ';')
+	}
+	return string(summary)
+}
@@ -0,0 +1,146 @@
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/dotenv"
+	jsonfile "github.com/Sufir/go-set-me-up/setup/source/json-file"
+)
+
+type ReloadConfig struct {
+	Started  time.Time `json:"started"`
+	Database *struct {
+		Host string `json:"host"`
+	} `json:"database"`
+	Name  string   `json:"name" env:"NAME"`
+	Level string   `json:"level" env:"LEVEL" validate:"oneof=debug|info"`
+	Tags  []string `json:"tags"`
+	Port  int      `json:"port" validate:"min=1"`
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReloader_Reload_SwapsValidatedCopyAndNotifies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name":"a","level":"info","port":80,"tags":["x"],"database":{"host":"db1"}}`)
+	reloader, err := New[ReloadConfig](setup.NewLoader(jsonfile.NewSource(path, setup.ModeOverride)))
+	require.NoError(t, err)
+	first := reloader.Current()
+	assert.Equal(t, "a", first.Name)
+
+	var changes []Change[ReloadConfig]
+	unsubscribe := reloader.Subscribe(func(change Change[ReloadConfig]) {
+		changes = append(changes, change)
+	})
+
+	writeFile(t, path, `{"name":"b","level":"info","port":81,"tags":["x","y"],"database":{"host":"db1"}}`)
+	require.NoError(t, reloader.Reload())
+	require.Len(t, changes, 1)
+	assert.Same(t, first, changes[0].Old)
+	assert.Same(t, reloader.Current(), changes[0].New)
+	assert.Equal(t, []string{"Name", "Tags", "Port"}, changes[0].Paths)
+	assert.Equal(t, "a", first.Name)
+
+	require.NoError(t, reloader.Reload())
+	assert.Len(t, changes, 1)
+
+	writeFile(t, path, `{"name":"c","level":"trace","port":0}`)
+	err = reloader.Reload()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrValidationFailed))
+	assert.Equal(t, "b", reloader.Current().Name)
+	assert.Len(t, changes, 1)
+
+	unsubscribe()
+	writeFile(t, path, `{"name":"d","level":"debug","port":82}`)
+	require.NoError(t, reloader.Reload())
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "d", reloader.Current().Name)
+}
+
+func TestReloader_New_FailsWhenInitialLoadFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+	_, err := New[ReloadConfig](setup.NewLoader(jsonfile.NewSource(path, setup.ModeOverride)))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+}
+
+func TestReloader_Watch_ReloadsWhenFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	writeFile(t, path, "APP_NAME=first\nAPP_LEVEL=info\n")
+	jsonPath := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, jsonPath, `{"port":80}`)
+
+	var mu sync.Mutex
+	var reported []error
+	reloader, err := New[ReloadConfig](
+		setup.NewLoader(jsonfile.NewSource(jsonPath, setup.ModeOverride), dotenv.NewSource(path, "app", ",", setup.ModeOverride)),
+		WithFiles(path, jsonPath),
+		WithInterval(5*time.Millisecond),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		}),
+	)
+	require.NoError(t, err)
+	changed := make(chan []string, 4)
+	reloader.Subscribe(func(change Change[ReloadConfig]) {
+		changed <- change.Paths
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- reloader.Watch(ctx)
+	}()
+
+	writeFile(t, path, "APP_NAME=second-value\nAPP_LEVEL=info\n")
+	select {
+	case paths := <-changed:
+		assert.Equal(t, []string{"Name"}, paths)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload after the file changed")
+	}
+	assert.Equal(t, "second-value", reloader.Current().Name)
+
+	writeFile(t, path, "APP_NAME=third\nAPP_LEVEL=verbose\n")
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(reported) > 0
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, "second-value", reloader.Current().Name)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestChangedPaths(t *testing.T) {
+	old := &ReloadConfig{Name: "a", Started: time.Unix(1, 0)}
+	updated := &ReloadConfig{Name: "a", Started: time.Unix(2, 0)}
+	updated.Database = &struct {
+		Host string `json:"host"`
+	}{Host: "x"}
+	assert.Equal(t, []string{"Started", "Database"}, ChangedPaths(old, updated))
+
+	old.Database = &struct {
+		Host string `json:"host"`
+	}{Host: "y"}
+	old.Started = updated.Started
+	assert.Equal(t, []string{"Database.Host"}, ChangedPaths(old, updated))
+	assert.Empty(t, ChangedPaths(updated, updated))
+}
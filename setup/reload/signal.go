@@ -0,0 +1,57 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+// OnSignal calls reload every time the process receives one of signals,
+// SIGHUP when none are given, and passes its result to report, which may be
+// nil. It blocks until ctx is cancelled, stops listening and returns
+// ctx.Err(). Signals that arrive while a reload is running are coalesced into
+// one more reload.
+//
+// reload is typically the Reload method of a setup.Holder or a Reloader, both
+// of which keep the last good value when loading fails.
+func OnSignal(ctx context.Context, reload func() error, report func(error), signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-received:
+		}
+		err := reload()
+		if report != nil {
+			report(err)
+		}
+	}
+}
+
+// LoadOnSignal runs loader into a new T every time the process receives one
+// of signals, SIGHUP when none are given, and passes the new value or the
+// load error, an AggregatedLoadFailedError, to report, which may be nil. The
+// value is nil when loading failed. Like OnSignal, it blocks until ctx is
+// cancelled and returns ctx.Err().
+func LoadOnSignal[T any](ctx context.Context, loader *setup.Loader, report func(*T, error), signals ...os.Signal) error {
+	return OnSignal(ctx, func() error {
+		fresh := new(T)
+		err := loader.Load(fresh)
+		if err != nil {
+			fresh = nil
+		}
+		if report != nil {
+			report(fresh, err)
+		}
+		return err
+	}, nil, signals...)
+}
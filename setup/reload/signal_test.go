@@ -0,0 +1,125 @@
+//go:build unix
+
+package reload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	jsonfile "github.com/Sufir/go-set-me-up/setup/source/json-file"
+)
+
+// keepSignal stops the test process from being killed by a signal sent
+// before the helper under test started listening.
+func keepSignal(t *testing.T, sig os.Signal) {
+	t.Helper()
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, sig)
+	t.Cleanup(func() {
+		signal.Stop(guard)
+	})
+}
+
+func TestLoadOnSignal_ReportsValuesAndErrors(t *testing.T) {
+	keepSignal(t, syscall.SIGUSR1)
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name":"a","level":"info","port":80}`)
+	loader := setup.NewLoader(jsonfile.NewSource(path, setup.ModeOverride))
+
+	type report struct {
+		value *ReloadConfig
+		err   error
+	}
+	reports := make(chan report, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- LoadOnSignal(ctx, loader, func(value *ReloadConfig, err error) {
+			reports <- report{value: value, err: err}
+		}, syscall.SIGUSR1)
+	}()
+
+	next := func() report {
+		t.Helper()
+		for {
+			require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+			select {
+			case got := <-reports:
+				return got
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	}
+
+	first := next()
+	require.NoError(t, first.err)
+	assert.Equal(t, "a", first.value.Name)
+
+	writeFile(t, path, `{"name":"b","level":"trace","port":80}`)
+	failed := next()
+	for failed.err == nil {
+		failed = next()
+	}
+	assert.Nil(t, failed.value)
+	assert.True(t, errors.Is(failed.err, setup.ErrLoadAggregatedFailed))
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestOnSignal_ReloadsHolder(t *testing.T) {
+	keepSignal(t, syscall.SIGUSR2)
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"name":"a","level":"info","port":80}`)
+	holder, err := setup.NewHolder[ReloadConfig](setup.NewLoader(jsonfile.NewSource(path, setup.ModeOverride)))
+	require.NoError(t, err)
+
+	reported := make(chan error, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- OnSignal(ctx, holder.Reload, func(err error) {
+			reported <- err
+		}, syscall.SIGUSR2)
+	}()
+
+	writeFile(t, path, `{"name":"b","level":"info","port":80}`)
+	require.Eventually(t, func() bool {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+		select {
+		case err := <-reported:
+			return err == nil && holder.Get().Name == "b"
+		case <-time.After(20 * time.Millisecond):
+			return false
+		}
+	}, 2*time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestLoadOnSignal_NilReport(t *testing.T) {
+	keepSignal(t, syscall.SIGWINCH)
+	path := filepath.Join(t.TempDir(), "missing.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- LoadOnSignal[ReloadConfig](ctx, setup.NewLoader(jsonfile.NewSource(path, setup.ModeOverride)), nil, syscall.SIGWINCH)
+	}()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGWINCH))
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,134 @@
+package setup
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// requiredField tracks whether some source supplied a field tagged required,
+// and which keys the sources looked it up under.
+type requiredField struct {
+	path     string
+	keys     []string
+	provided bool
+}
+
+// requiredTracker observes every source of one load and reports the fields
+// tagged required:"true" that no source supplied. A value taken from a
+// default tag does not count as supplied; an explicit zero value does.
+// Sources that are not ObservableSource are credited with every required
+// field whose value they changed.
+type requiredTracker struct {
+	index  map[string]int
+	fields []requiredField
+}
+
+// newRequiredTracker returns nil when cfg declares no required fields.
+func newRequiredTracker(cfg any) *requiredTracker {
+	var tracker *requiredTracker
+	walkLeafPaths(cfg, func(path string, fieldInfo reflect.StructField) {
+		if required, _ := strconv.ParseBool(fieldInfo.Tag.Get("required")); !required {
+			return
+		}
+		if tracker == nil {
+			tracker = &requiredTracker{index: make(map[string]int)}
+		}
+		tracker.index[path] = len(tracker.fields)
+		tracker.fields = append(tracker.fields, requiredField{path: path})
+	})
+	return tracker
+}
+
+func (tracker *requiredTracker) ObserveField(event FieldEvent) {
+	position, ok := tracker.index[event.Path]
+	if !ok {
+		return
+	}
+	field := &tracker.fields[position]
+	if event.Key != "" && !containsKey(field.keys, event.Key) {
+		field.keys = append(field.keys, event.Key)
+	}
+	if event.Found && event.Assigned {
+		field.provided = true
+	}
+}
+
+// snapshot copies the current values of the required fields before a source
+// that does not report its assignments runs. Fields inside nil pointers are
+// recorded as invalid values. It returns nil when there is nothing to track.
+func (tracker *requiredTracker) snapshot(cfg any) []reflect.Value {
+	if tracker == nil {
+		return nil
+	}
+	root := reflect.ValueOf(cfg)
+	values := make([]reflect.Value, len(tracker.fields))
+	for i, field := range tracker.fields {
+		if value, ok := valueAtPath(root, field.path); ok {
+			values[i] = reflect.New(value.Type()).Elem()
+			values[i].Set(deepCopy(value))
+		}
+	}
+	return values
+}
+
+// creditChanged marks the required fields whose value differs from before as
+// provided. A source that sets a field to the value it already held cannot be
+// told apart from one that left it alone, and is not credited.
+func (tracker *requiredTracker) creditChanged(cfg any, before []reflect.Value) {
+	if before == nil {
+		return
+	}
+	root := reflect.ValueOf(cfg)
+	for i := range tracker.fields {
+		value, ok := valueAtPath(root, tracker.fields[i].path)
+		if !ok {
+			continue
+		}
+		previous := before[i]
+		if !previous.IsValid() {
+			previous = reflect.Zero(value.Type())
+		}
+		if !reflect.DeepEqual(previous.Interface(), value.Interface()) {
+			tracker.fields[i].provided = true
+		}
+	}
+}
+
+// errors returns one RequiredMissingError per required field that no source
+// supplied. Fields inside nil pointers, such as commands that were not
+// selected, are not reported.
+func (tracker *requiredTracker) errors(cfg any) []error {
+	if tracker == nil {
+		return nil
+	}
+	var missing []error
+	root := reflect.ValueOf(cfg)
+	for _, field := range tracker.fields {
+		if field.provided {
+			continue
+		}
+		if _, reachable := valueAtPath(root, field.path); !reachable {
+			continue
+		}
+		missing = append(missing, NewRequiredMissingError(field.path, field.keys))
+	}
+	return missing
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, existing := range keys {
+		if existing == key {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldObservers sends every event to each observer in order.
+type fieldObservers []FieldObserver
+
+func (observers fieldObservers) ObserveField(event FieldEvent) {
+	for _, observer := range observers {
+		observer.ObserveField(event)
+	}
+}
@@ -0,0 +1,106 @@
+package setup_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	"github.com/Sufir/go-set-me-up/setup/source/flags"
+	jsonfile "github.com/Sufir/go-set-me-up/setup/source/json-file"
+)
+
+func TestLoader_Required_UsesProvenanceNotZeroValues(t *testing.T) {
+	jsonContent := []byte(`{"Debug":false,"Server":{}}`)
+	tempFilePath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(tempFilePath, jsonContent, 0o644))
+
+	type RequiredConfiguration struct {
+		Token  string `env:"TOKEN" flag:"token" required:"true"`
+		Level  string `flag:"level" flagDefault:"info" required:"true"`
+		Note   string `required:"false"`
+		Server struct {
+			Port int `json:"port" env:"PORT" flag:"port" required:"true"`
+		} `json:"Server" envSegment:"server"`
+		Retries int  `env:"RETRIES" required:"true"`
+		Debug   bool `json:"Debug" required:"true"`
+	}
+
+	t.Setenv("APP_RETRIES", "0")
+	loader := pkg.NewLoader(
+		jsonfile.NewSource(tempFilePath, pkg.ModeOverride),
+		env.NewSource("app", ",", pkg.ModeOverride),
+		flags.NewSourceWithArgs(pkg.ModeOverride, nil),
+	)
+	configuration := &RequiredConfiguration{Token: "preset"}
+	loadError := loader.Load(configuration)
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrRequiredMissing))
+	var requiredError *pkg.RequiredMissingError
+	require.True(t, errors.As(loadError, &requiredError))
+	assert.Equal(t, "Token", requiredError.Path)
+	assert.Equal(t, []string{"APP_TOKEN", "--token"}, requiredError.Keys)
+
+	message := loadError.Error()
+	assert.Contains(t, message, "field Server.Port is required; set one of: Server.port, APP_SERVER_PORT, --port")
+	assert.Contains(t, message, "field Token is required; set one of: APP_TOKEN, --token")
+	assert.Contains(t, message, "field Level is required; set one of: --level")
+	assert.NotContains(t, message, "Retries")
+	assert.NotContains(t, message, "Debug")
+	assert.NotContains(t, message, "Note")
+
+	t.Setenv("APP_TOKEN", "")
+	loader = pkg.NewLoader(
+		env.NewSource("app", ",", pkg.ModeOverride),
+		flags.NewSourceWithArgs(pkg.ModeOverride, []string{"--port", "0", "--level", "warn"}),
+		jsonfile.NewSource(tempFilePath, pkg.ModeFillMissing),
+	)
+	require.NoError(t, loader.Load(&RequiredConfiguration{}))
+
+	type UnreachableConfiguration struct {
+		Serve *struct {
+			Port int `flag:"port" required:"true"`
+		} `cmd:"serve"`
+		Check *struct {
+			Strict bool `flag:"strict"`
+		} `cmd:"check"`
+		Custom int `required:"true"`
+	}
+	loader = pkg.NewLoader(flags.NewSourceWithArgs(pkg.ModeOverride, []string{"check"}))
+	loadError = loader.Load(&UnreachableConfiguration{})
+	require.Error(t, loadError)
+	assert.NotContains(t, loadError.Error(), "Serve.Port")
+	assert.Contains(t, loadError.Error(), "field Custom is required but no source can provide it")
+}
+
+func TestLoader_Required_CreditsSourcesWithoutObserver(t *testing.T) {
+	type RequiredConfiguration struct {
+		Server *struct {
+			Host string `required:"true"`
+		}
+		Token string `required:"true"`
+		Port  int    `required:"true"`
+	}
+	plainSource := sourceFunc(func(target any) error {
+		configuration := target.(*RequiredConfiguration)
+		configuration.Token = "secret"
+		configuration.Port = 8080
+		return nil
+	})
+
+	configuration := &RequiredConfiguration{Port: 8080}
+	loadError := pkg.NewLoader(plainSource).Load(configuration)
+	require.Error(t, loadError)
+	message := loadError.Error()
+	assert.NotContains(t, message, "field Token")
+	assert.Contains(t, message, "field Port is required but no source can provide it")
+	assert.NotContains(t, message, "Server")
+	assert.Equal(t, "secret", configuration.Token)
+
+	require.NoError(t, pkg.NewLoader(plainSource).Load(&RequiredConfiguration{}))
+}
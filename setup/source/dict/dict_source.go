@@ -10,9 +10,10 @@ import (
 )
 
 type Source struct {
-	dict   map[string]any
-	caster setup.TypeCaster
-	mode   setup.LoadMode
+	dict     map[string]any
+	caster   setup.TypeCaster
+	observer setup.FieldObserver
+	mode     setup.LoadMode
 }
 
 func NewSource(dict map[string]any, mode setup.LoadMode) *Source {
@@ -32,20 +33,27 @@ func NewSourceWithCaster(dict map[string]any, mode setup.LoadMode, caster setup.
 	return &Source{dict: dict, caster: caster, mode: sourceutil.DefaultMode(mode)}
 }
 
+// WithFieldObserver returns a copy of the source that reports every leaf value
+// found in the map to observer, keyed by its dotted map key.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
 func (source Source) Load(cfg any) error {
 	e, err := sourceutil.EnsureTargetStruct(cfg)
 	if err != nil {
 		return err
 	}
 	var errs []error
-	source.loadStruct(e, source.dict, source.mode, &errs, "")
+	source.loadStruct(e, source.dict, source.mode, &errs, "", "")
 	if len(errs) > 0 {
 		return setup.NewAggregatedLoadFailedError(errors.Join(errs...))
 	}
 	return nil
 }
 
-func (source Source) loadStruct(structValue reflect.Value, dict map[string]any, mode setup.LoadMode, errs *[]error, prefix string) {
+func (source Source) loadStruct(structValue reflect.Value, dict map[string]any, mode setup.LoadMode, errs *[]error, prefix string, keyPrefix string) {
 	t := structValue.Type()
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
@@ -53,46 +61,55 @@ func (source Source) loadStruct(structValue reflect.Value, dict map[string]any,
 			continue
 		}
 		fv := structValue.Field(i)
-		raw, ok := source.lookupValue(dict, f.Name)
+		key, raw, ok := source.lookupValue(dict, f.Name)
 		if !ok {
 			continue
 		}
+		path := sourceutil.MakePath(prefix, f.Name)
+		key = sourceutil.MakePath(keyPrefix, key)
 		if m, isMap := asMapStringAny(raw); isMap {
 			if fv.Kind() == reflect.Struct {
-				source.loadStruct(fv, m, mode, errs, sourceutil.MakePath(prefix, f.Name))
+				source.loadStruct(fv, m, mode, errs, path, key)
 				continue
 			}
 			if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
 				if fv.IsNil() {
 					fv.Set(reflect.New(fv.Type().Elem()))
 				}
-				source.loadStruct(fv.Elem(), m, mode, errs, sourceutil.MakePath(prefix, f.Name))
+				source.loadStruct(fv.Elem(), m, mode, errs, path, key)
 				continue
 			}
 			continue
 		}
+		event := setup.FieldEvent{Path: path, Key: key, Found: true}
 		if !sourceutil.ShouldAssign(fv, true, mode, "") {
+			sourceutil.Observe(source.observer, event)
 			continue
 		}
+		event.Eligible = true
 		if err := sourceutil.AssignFromAny(source.caster, fv, raw); err != nil {
-			*errs = append(*errs, setup.NewDictFieldFailedError(sourceutil.MakePath(prefix, f.Name), err))
+			*errs = append(*errs, setup.NewDictFieldFailedError(path, err))
+			event.Err = err
+		} else {
+			event.Assigned = true
 		}
+		sourceutil.Observe(source.observer, event)
 	}
 }
 
-func (source Source) lookupValue(dict map[string]any, fieldName string) (any, bool) {
+func (source Source) lookupValue(dict map[string]any, fieldName string) (string, any, bool) {
 	if v, ok := dict[fieldName]; ok {
-		return v, true
+		return fieldName, v, true
 	}
 	upper := sourceutil.ConvertToUpperSnake(fieldName)
 	lower := strings.ToLower(upper)
 	if v, ok := dict[lower]; ok {
-		return v, true
+		return lower, v, true
 	}
 	if v, ok := dict[upper]; ok {
-		return v, true
+		return upper, v, true
 	}
-	return nil, false
+	return "", nil, false
 }
 
 func asMapStringAny(v any) (map[string]any, bool) {
@@ -0,0 +1,336 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+)
+
+var errUnterminatedReference = errors.New("unterminated variable reference")
+
+func errUnterminated(quote string) error {
+	return fmt.Errorf("unterminated %s-quoted value", quote)
+}
+
+func errInvalidReference(body string) error {
+	return fmt.Errorf("invalid variable reference ${%s}", body)
+}
+
+type parser struct {
+	values      map[string]string
+	lines       map[string]int
+	environment env.LookupFunc
+	data        string
+	pos         int
+	line        int
+}
+
+// parse reads KEY=value entries from dotenv content and returns the values
+// together with the line each key was last set on. Values may be unquoted,
+// single-quoted (literal) or double-quoted (escapes and expansion). References
+// such as ${VAR}, ${VAR:-default} and $VAR resolve against keys defined earlier
+// in the file and then through environment.
+func parse(data string, environment env.LookupFunc) (map[string]string, map[string]int, error) {
+	p := &parser{
+		data:        strings.ReplaceAll(data, "\r\n", "\n"),
+		line:        1,
+		values:      map[string]string{},
+		lines:       map[string]int{},
+		environment: environment,
+	}
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return p.values, p.lines, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		if err := p.parseEntry(); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (p *parser) parseEntry() error {
+	startLine := p.line
+	key := p.readKey()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.readKey()
+	}
+	if key == "" {
+		return setup.NewDotenvSyntaxError(startLine, "expected variable name")
+	}
+	p.skipSpaces()
+	if p.eof() || p.peek() != '=' {
+		return setup.NewDotenvSyntaxError(startLine, "expected '=' after "+key)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var value string
+	var err error
+	switch {
+	case p.eof() || p.peek() == '\n':
+		value = ""
+	case p.peek() == '\'':
+		value, err = p.readSingleQuoted()
+	case p.peek() == '"':
+		value, err = p.readDoubleQuoted()
+	default:
+		value, err = p.readUnquoted()
+	}
+	if err != nil {
+		return setup.NewDotenvSyntaxError(startLine, err.Error())
+	}
+	if rest := p.restOfLine(); rest != "" && !strings.HasPrefix(rest, "#") {
+		return setup.NewDotenvSyntaxError(startLine, "unexpected characters after value of "+key)
+	}
+	p.values[key] = value
+	p.lines[key] = startLine
+	return nil
+}
+
+func (p *parser) readKey() string {
+	start := p.pos
+	for !p.eof() && isKeyChar(p.peek()) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func (p *parser) readSingleQuoted() (string, error) {
+	p.pos++
+	end := strings.IndexByte(p.data[p.pos:], '\'')
+	if end < 0 {
+		return "", errUnterminated("single")
+	}
+	value := p.data[p.pos : p.pos+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *parser) readDoubleQuoted() (string, error) {
+	p.pos++
+	var builder strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return builder.String(), nil
+		case '\\':
+			if p.pos+1 >= len(p.data) {
+				return "", errUnterminated("double")
+			}
+			builder.WriteString(unescape(p.data[p.pos+1]))
+			p.pos += 2
+		case '$':
+			expanded, next, err := p.expandReference(p.data, p.pos, true)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(expanded)
+			p.pos = next
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			builder.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", errUnterminated("double")
+}
+
+func (p *parser) readUnquoted() (string, error) {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		if p.peek() == '#' && p.pos > start && isSpace(p.data[p.pos-1]) {
+			break
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.data[start:p.pos])
+	return p.expand(raw)
+}
+
+func (p *parser) expand(input string) (string, error) {
+	if !strings.Contains(input, "$") {
+		return input, nil
+	}
+	var builder strings.Builder
+	i := 0
+	for i < len(input) {
+		if input[i] != '$' {
+			builder.WriteByte(input[i])
+			i++
+			continue
+		}
+		expanded, next, err := p.expandReference(input, i, false)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(expanded)
+		i = next
+	}
+	return builder.String(), nil
+}
+
+// expandReference resolves the reference that starts with '$' at input[start]
+// and returns its value together with the index right after the reference.
+// quoted is set when input continues past a double-quoted value, whose
+// closing quote ends the reference.
+func (p *parser) expandReference(input string, start int, quoted bool) (string, int, error) {
+	i := start + 1
+	if i < len(input) && input[i] == '{' {
+		end := closingBrace(input, i, quoted)
+		if end < 0 {
+			return "", 0, errUnterminatedReference
+		}
+		body := input[i+1 : end]
+		next := end + 1
+		name, fallback, hasFallback := strings.Cut(body, ":-")
+		if !isValidName(name) {
+			return "", 0, errInvalidReference(body)
+		}
+		value, ok := p.lookup(name)
+		if (!ok || value == "") && hasFallback {
+			expanded, err := p.expand(fallback)
+			return expanded, next, err
+		}
+		return value, next, nil
+	}
+	nameStart := i
+	for i < len(input) && isNameChar(input[i]) {
+		i++
+	}
+	if i == nameStart {
+		return "$", i, nil
+	}
+	value, _ := p.lookup(input[nameStart:i])
+	return value, i, nil
+}
+
+// closingBrace returns the index of the '}' that closes the '{' at
+// input[open], skipping references nested in a default value. It returns -1
+// when the line or, for a quoted value, the value ends first.
+func closingBrace(input string, open int, quoted bool) int {
+	depth := 1
+	for i := open + 1; i < len(input); i++ {
+		switch input[i] {
+		case '{':
+			if input[i-1] == '$' {
+				depth++
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '\n':
+			return -1
+		case '"':
+			if quoted {
+				return -1
+			}
+		case '\\':
+			if quoted {
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+func (p *parser) lookup(name string) (string, bool) {
+	if value, ok := p.values[name]; ok {
+		return value, true
+	}
+	return p.environment(name)
+}
+
+func (p *parser) restOfLine() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+	return strings.TrimSpace(p.data[start:p.pos])
+}
+
+func (p *parser) skipBlank() {
+	for !p.eof() {
+		c := p.peek()
+		if c == '\n' {
+			p.line++
+		} else if !isSpace(c) {
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$':
+		return string(c)
+	default:
+		return "\\" + string(c)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isKeyChar(c byte) bool {
+	return isNameChar(c) || c == '.' || c == '-'
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package dotenv
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+func TestParse_Values(t *testing.T) {
+	testCases := []struct {
+		expected map[string]string
+		name     string
+		input    string
+	}{
+		{name: "Unquoted", input: "A=1\nB = two words \n", expected: map[string]string{"A": "1", "B": "two words"}},
+		{name: "Empty", input: "A=\nB=\"\"\nC=''", expected: map[string]string{"A": "", "B": "", "C": ""}},
+		{name: "CommentsAndBlankLines", input: "# header\n\nA=1 # trailing\n  # indented\nB=x#y\n", expected: map[string]string{"A": "1", "B": "x#y"}},
+		{name: "ExportPrefix", input: "export A=1\nexport\tB=2\nexporter=3\n", expected: map[string]string{"A": "1", "B": "2", "exporter": "3"}},
+		{name: "SingleQuotedIsLiteral", input: `A='${HOME} \n "x"'`, expected: map[string]string{"A": `${HOME} \n "x"`}},
+		{name: "DoubleQuotedEscapes", input: `A="line1\nline2\t\"q\" \\ \$x \q"`, expected: map[string]string{"A": "line1\nline2\t\"q\" \\ $x \\q"}},
+		{name: "DoubleQuotedMultiline", input: "A=\"first\nsecond\"\nB=after", expected: map[string]string{"A": "first\nsecond", "B": "after"}},
+		{name: "QuotedWithComment", input: `A="v" # note`, expected: map[string]string{"A": "v"}},
+		{name: "ExpansionFromFile", input: "HOST=localhost\nURL=http://${HOST}:$PORT/\nPORT=80\nQ=\"${HOST}\"", expected: map[string]string{"HOST": "localhost", "URL": "http://localhost:/", "PORT": "80", "Q": "localhost"}},
+		{name: "ExpansionFallback", input: "A=${MISSING_DOTENV_VAR:-fallback}\nB=\"${A:-unused}\"\nC=${EMPTY:-x}\nEMPTY=", expected: map[string]string{"A": "fallback", "B": "fallback", "C": "x", "EMPTY": ""}},
+		{name: "NestedFallback", input: "C=inner\nA=${MISSING_DOTENV_VAR:-${C}}\nB=\"${MISSING_DOTENV_VAR:-${ALSO_MISSING:-deep}}/x\"\nD=${MISSING_DOTENV_VAR:-{literal}}", expected: map[string]string{"C": "inner", "A": "inner", "B": "deep/x", "D": "{literal}"}},
+		{name: "DollarWithoutName", input: "A=cost $ 5\nB=\"$\"", expected: map[string]string{"A": "cost $ 5", "B": "$"}},
+		{name: "CRLF", input: "A=1\r\nB=2\r\n", expected: map[string]string{"A": "1", "B": "2"}},
+		{name: "DottedKeys", input: "app.name=x\napp-port=1", expected: map[string]string{"app.name": "x", "app-port": "1"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			values, _, err := parse(testCase.input, os.LookupEnv)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, values)
+		})
+	}
+}
+
+func TestParse_ProcessEnvironmentExpansion(t *testing.T) {
+	t.Setenv("DOTENV_PARSER_OUTER", "outer")
+	values, _, err := parse("A=${DOTENV_PARSER_OUTER}/x\nDOTENV_PARSER_OUTER=file\nB=$DOTENV_PARSER_OUTER", os.LookupEnv)
+	require.NoError(t, err)
+	assert.Equal(t, "outer/x", values["A"])
+	assert.Equal(t, "file", values["B"])
+}
+
+func TestParse_SyntaxErrors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		input        string
+		expectedText string
+		expectedLine int
+	}{
+		{name: "MissingEquals", input: "A=1\nB\n", expectedLine: 2, expectedText: "expected '=' after B"},
+		{name: "MissingName", input: "=1", expectedLine: 1, expectedText: "expected variable name"},
+		{name: "UnterminatedDouble", input: "A=1\n\nB=\"abc\n", expectedLine: 3, expectedText: "unterminated double-quoted value"},
+		{name: "UnterminatedSingle", input: "A='abc", expectedLine: 1, expectedText: "unterminated single-quoted value"},
+		{name: "TrailingGarbage", input: "A=\"x\" y", expectedLine: 1, expectedText: "unexpected characters after value of A"},
+		{name: "UnterminatedReference", input: "A=${B", expectedLine: 1, expectedText: "unterminated variable reference"},
+		{name: "UnterminatedNestedReference", input: "A=${B:-${C}", expectedLine: 1, expectedText: "unterminated variable reference"},
+		{name: "ReferencePastClosingQuote", input: "A=\"${B\" }", expectedLine: 1, expectedText: "unterminated variable reference"},
+		{name: "InvalidReference", input: "A=${B C}", expectedLine: 1, expectedText: "invalid variable reference ${B C}"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := parse(testCase.input, os.LookupEnv)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, setup.ErrDotenvSyntax))
+			var syntaxError *setup.DotenvSyntaxError
+			require.True(t, errors.As(err, &syntaxError))
+			assert.Equal(t, testCase.expectedLine, syntaxError.Line)
+			assert.Equal(t, testCase.expectedText, syntaxError.Reason)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+type Source struct {
+	environment *env.Source
+	lookup      env.LookupFunc
+	path        string
+}
+
+func NewSource(path string, prefix string, delimiter string, mode setup.LoadMode) *Source {
+	return &Source{path: path, environment: env.NewSource(prefix, delimiter, mode)}
+}
+
+func NewSourceWithCaster(path string, prefix string, delimiter string, mode setup.LoadMode, caster setup.TypeCaster) *Source {
+	return &Source{path: path, environment: env.NewSourceWithCaster(prefix, delimiter, mode, caster)}
+}
+
+// NewSourceWithLookup creates a source whose ${VAR} and $VAR references to
+// keys not defined in the file resolve through lookup instead of the process
+// environment. A nil lookup keeps the default behavior.
+func NewSourceWithLookup(path string, prefix string, delimiter string, mode setup.LoadMode, lookup env.LookupFunc) *Source {
+	source := NewSource(path, prefix, delimiter, mode)
+	source.lookup = lookup
+	return source
+}
+
+func NewSourceWithCasterAndLookup(path string, prefix string, delimiter string, mode setup.LoadMode, caster setup.TypeCaster, lookup env.LookupFunc) *Source {
+	source := NewSourceWithCaster(path, prefix, delimiter, mode, caster)
+	source.lookup = lookup
+	return source
+}
+
+// WithFieldObserver returns a copy of the source whose underlying environment
+// reader reports every leaf field to observer.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	if environment, ok := source.environment.WithFieldObserver(observer).(*env.Source); ok {
+		source.environment = environment
+	}
+	return &source
+}
+
+func (source Source) Load(cfg any) error {
+	if _, err := sourceutil.EnsureTargetStruct(cfg); err != nil {
+		return err
+	}
+
+	data, readErr := os.ReadFile(source.path)
+	if readErr != nil {
+		return setup.NewAggregatedLoadFailedError(readErr)
+	}
+
+	lookup := source.lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	values, lines, parseErr := parse(string(data), lookup)
+	if parseErr != nil {
+		return setup.NewAggregatedLoadFailedError(fmt.Errorf("%s: %w", source.path, parseErr))
+	}
+
+	return source.withLocations(source.environment.LoadFrom(cfg, values), lines)
+}
+
+// withLocations rewrites the field errors of the environment reader as
+// dotenv field errors that name the file and the line of the entry.
+func (source Source) withLocations(loadErr error, lines map[string]int) error {
+	var aggregated *setup.AggregatedLoadFailedError
+	if !errors.As(loadErr, &aggregated) {
+		return loadErr
+	}
+	joined, ok := aggregated.Aggregated.(interface{ Unwrap() []error })
+	if !ok {
+		return loadErr
+	}
+	collected := joined.Unwrap()
+	located := make([]error, 0, len(collected))
+	for _, err := range collected {
+		var fieldErr *setup.SourceFieldFailedError
+		if errors.As(err, &fieldErr) && fieldErr.SourceName == "env" {
+			err = setup.NewDotenvFieldFailedError(source.path, lines[fieldErr.Key], fieldErr.Key, fieldErr.Value, fieldErr.Path, fieldErr.OriginalError)
+		}
+		located = append(located, err)
+	}
+	return setup.NewAggregatedLoadFailedError(errors.Join(located...))
+}
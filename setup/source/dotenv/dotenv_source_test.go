@@ -0,0 +1,140 @@
+package dotenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	"github.com/Sufir/go-set-me-up/setup/source/testcommon"
+)
+
+func writeDotenvFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+type DotenvConfig struct {
+	Name     string `env:"NAME"`
+	Skip     string `env:"-"`
+	Database struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT" envDefault:"5432"`
+	} `envSegment:"db"`
+	Tags  []string `env:"TAGS" envDelim:";"`
+	Ports []int    `env:"PORTS"`
+	Debug bool     `env:"DEBUG"`
+}
+
+func TestDotenvSource_UsesEnvTagSemantics(t *testing.T) {
+	path := writeDotenvFile(t, `
+# local development
+export APP_NAME="demo service"
+APP_DEBUG=true
+APP_DB_HOST=db.local
+APP_TAGS=a; b ;c
+APP_PORTS=80|443
+APP_SKIP=ignored
+OTHER_NAME=ignored
+`)
+	cfg := &DotenvConfig{}
+	err := NewSource(path, "app", "|", setup.ModeOverride).Load(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "demo service", cfg.Name)
+	assert.Equal(t, true, cfg.Debug)
+	assert.Equal(t, "db.local", cfg.Database.Host)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Tags)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, "", cfg.Skip)
+}
+
+func TestDotenvSource_SameStructAsEnvSource(t *testing.T) {
+	path := writeDotenvFile(t, "APP_OUTER_INNER_VALUE=123\n")
+	fromFile := &testcommon.RootNested{}
+	require.NoError(t, NewSource(path, "app", ",", setup.ModeOverride).Load(fromFile))
+
+	t.Setenv("APP_OUTER_INNER_VALUE", "123")
+	fromProcess := &testcommon.RootNested{}
+	require.NoError(t, env.NewSource("app", ",", setup.ModeOverride).Load(fromProcess))
+
+	assert.Equal(t, fromProcess, fromFile)
+	assert.Equal(t, 123, fromFile.Outer.Inner.Value)
+}
+
+func TestDotenvSource_DoesNotReadProcessEnvironmentForFields(t *testing.T) {
+	t.Setenv("APP_PORT", "9999")
+	path := writeDotenvFile(t, "APP_NAME=${APP_PORT}\n")
+	cfg := &testcommon.BasicTypesConfiguration{}
+	require.NoError(t, NewSource(path, "app", ",", setup.ModeOverride).Load(cfg))
+	assert.Equal(t, 0, cfg.Port)
+	assert.Equal(t, "9999", cfg.Name)
+}
+
+func TestDotenvSource_Mode(t *testing.T) {
+	path := writeDotenvFile(t, "A=10\nB=20\n")
+
+	cfg := &testcommon.ModeBehaviorConfiguration{A: 5, B: testcommon.IntPointer(7)}
+	require.NoError(t, NewSource(path, "", ",", setup.ModeFillMissing).Load(cfg))
+	assert.Equal(t, 5, cfg.A)
+	assert.Equal(t, 7, *cfg.B)
+
+	require.NoError(t, NewSource(path, "", ",", setup.ModeOverride).Load(cfg))
+	assert.Equal(t, 10, cfg.A)
+	assert.Equal(t, 20, *cfg.B)
+}
+
+func TestDotenvSource_Errors(t *testing.T) {
+	cfg := &testcommon.BasicTypesConfiguration{}
+
+	fieldPath := writeDotenvFile(t, "# ports\nAPP_NAME=svc\n\nAPP_PORT=x\n")
+	err := NewSource(fieldPath, "app", ",", setup.ModeOverride).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrSourceFieldFailed))
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+	var fieldErr *setup.SourceFieldFailedError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "dotenv", fieldErr.SourceName)
+	assert.Equal(t, fieldPath, fieldErr.File)
+	assert.Equal(t, 4, fieldErr.Line)
+	assert.Equal(t, "APP_PORT", fieldErr.Key)
+	assert.Contains(t, err.Error(), "dotenv "+fieldPath+":4 APP_PORT=x field Port")
+
+	path := writeDotenvFile(t, "APP_PORT=1\nAPP_NAME=\"open\n")
+	err = NewSource(path, "app", ",", setup.ModeOverride).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+	assert.True(t, errors.Is(err, setup.ErrDotenvSyntax))
+	assert.Contains(t, err.Error(), path+": ")
+	assert.Contains(t, err.Error(), "line 2")
+
+	err = NewSource(filepath.Join(t.TempDir(), "missing.env"), "app", ",", setup.ModeOverride).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	err = NewSource(path, "app", ",", setup.ModeOverride).Load(*cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+}
+
+func TestDotenvSource_ExpandsThroughInjectedLookup(t *testing.T) {
+	t.Setenv("DOTENV_LOOKUP_HOST", "process")
+	path := writeDotenvFile(t, "APP_NAME=${DOTENV_LOOKUP_HOST}-${DOTENV_LOOKUP_MISSING:-fallback}\n")
+	lookup := env.MapLookup(map[string]string{"DOTENV_LOOKUP_HOST": "injected"})
+
+	cfg := &DotenvConfig{}
+	require.NoError(t, NewSourceWithLookup(path, "app", ",", setup.ModeOverride, lookup).Load(cfg))
+	assert.Equal(t, "injected-fallback", cfg.Name)
+
+	cfg = &DotenvConfig{}
+	require.NoError(t, NewSourceWithLookup(path, "app", ",", setup.ModeOverride, nil).Load(cfg))
+	assert.Equal(t, "process-fallback", cfg.Name)
+}
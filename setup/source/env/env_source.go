@@ -10,8 +10,14 @@ import (
 	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
 )
 
+// LookupFunc resolves the value of an environment variable by its full key.
+// The boolean result reports whether the variable is present.
+type LookupFunc func(key string) (string, bool)
+
 type Source struct {
 	caster    setup.TypeCaster
+	observer  setup.FieldObserver
+	lookup    LookupFunc
 	prefix    string
 	delimiter string
 	mode      setup.LoadMode
@@ -54,20 +60,68 @@ func NewSourceWithCaster(prefix string, delimiter string, mode setup.LoadMode, c
 	}
 }
 
+// NewSourceWithLookup creates a source that resolves variables through lookup
+// instead of the process environment. A nil lookup keeps the default behavior.
+func NewSourceWithLookup(prefix string, delimiter string, mode setup.LoadMode, lookup LookupFunc) *Source {
+	source := NewSource(prefix, delimiter, mode)
+	source.lookup = lookup
+	return source
+}
+
+func NewSourceWithCasterAndLookup(prefix string, delimiter string, mode setup.LoadMode, caster setup.TypeCaster, lookup LookupFunc) *Source {
+	source := NewSourceWithCaster(prefix, delimiter, mode, caster)
+	source.lookup = lookup
+	return source
+}
+
+// MapLookup returns a LookupFunc backed by a fixed environment snapshot.
+func MapLookup(environment map[string]string) LookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := environment[key]
+		return value, ok
+	}
+}
+
+// EnvironLookup returns a LookupFunc backed by an environment block in the
+// "KEY=value" form used by os.Environ and exec.Cmd.Env.
+func EnvironLookup(environ []string) LookupFunc {
+	return MapLookup(parseEnviron(environ))
+}
+
+// WithFieldObserver returns a copy of the source that reports every leaf field
+// to observer, with the environment variable name as the key.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
 func (source Source) Load(cfg any) error {
+	lookup := source.lookup
+	if lookup == nil {
+		lookup = MapLookup(getEnv())
+	}
+	return source.load(cfg, lookup)
+}
+
+// LoadFrom applies values from the given environment map instead of the process
+// environment. Keys are resolved with the same prefix, segment and tag rules as Load.
+func (source Source) LoadFrom(cfg any, environment map[string]string) error {
+	return source.load(cfg, MapLookup(environment))
+}
+
+func (source Source) load(cfg any, lookup LookupFunc) error {
 	elem, err := sourceutil.EnsureTargetStruct(cfg)
 	if err != nil {
 		return err
 	}
 
-	environment := getEnv()
 	var collected []error
 	segments := []string{}
 	if source.prefix != "" {
 		segments = append(segments, source.prefix)
 	}
 
-	source.loadStruct(elem, segments, environment, source.mode, &collected, "")
+	source.loadStruct(elem, segments, lookup, source.mode, &collected, "")
 
 	if len(collected) > 0 {
 		return setup.NewAggregatedLoadFailedError(errors.Join(collected...))
@@ -76,7 +130,10 @@ func (source Source) Load(cfg any) error {
 }
 
 func getEnv() map[string]string {
-	environment := os.Environ()
+	return parseEnviron(os.Environ())
+}
+
+func parseEnviron(environment []string) map[string]string {
 	result := make(map[string]string, len(environment))
 
 	for _, pair := range environment {
@@ -92,7 +149,7 @@ func getEnv() map[string]string {
 	return result
 }
 
-func (source Source) loadStruct(structValue reflect.Value, segments []string, env map[string]string, mode setup.LoadMode, errs *[]error, prefix string) {
+func (source Source) loadStruct(structValue reflect.Value, segments []string, lookup LookupFunc, mode setup.LoadMode, errs *[]error, prefix string) {
 	structType := structValue.Type()
 	for i := 0; i < structType.NumField(); i++ {
 		fieldInfo := structType.Field(i)
@@ -103,14 +160,14 @@ func (source Source) loadStruct(structValue reflect.Value, segments []string, en
 			continue
 		}
 		fieldValue := structValue.Field(i)
-		if source.processLeafField(fieldValue, fieldInfo, segments, env, mode, errs, prefix) {
+		if source.processLeafField(fieldValue, fieldInfo, segments, lookup, mode, errs, prefix) {
 			continue
 		}
 		nestedValue, nextSegments, ok := source.resolveNestedStruct(fieldValue, fieldInfo, segments)
 		if !ok {
 			continue
 		}
-		source.loadStruct(nestedValue, nextSegments, env, mode, errs, sourceutil.MakePath(prefix, fieldInfo.Name))
+		source.loadStruct(nestedValue, nextSegments, lookup, mode, errs, sourceutil.MakePath(prefix, fieldInfo.Name))
 	}
 }
 
@@ -151,23 +208,28 @@ func (source Source) resolveNestedStruct(fieldValue reflect.Value, fieldInfo ref
 	}
 }
 
-func (source Source) processLeafField(fieldValue reflect.Value, fieldInfo reflect.StructField, segments []string, env map[string]string, mode setup.LoadMode, errs *[]error, prefix string) bool {
+func (source Source) processLeafField(fieldValue reflect.Value, fieldInfo reflect.StructField, segments []string, lookup LookupFunc, mode setup.LoadMode, errs *[]error, prefix string) bool {
 	tagEnv := fieldInfo.Tag.Get("env")
 	if tagEnv == "" {
 		return false
 	}
 	leaf := sourceutil.ConvertToEnvVar(tagEnv)
 	key := buildKey(segments, leaf)
-	val, ok := env[key]
+	val, ok := lookup(key)
 	defaultValue := fieldInfo.Tag.Get("envDefault")
+	path := sourceutil.MakePath(prefix, fieldInfo.Name)
+	event := setup.FieldEvent{Path: path, Key: key, Found: ok}
 	if !sourceutil.ShouldAssign(fieldValue, ok, mode, defaultValue) {
+		sourceutil.Observe(source.observer, event)
 		return true
 	}
+	event.Eligible = true
 	setValue := ""
 	if ok {
 		setValue = val
 	} else {
 		if defaultValue == "" {
+			sourceutil.Observe(source.observer, event)
 			return true
 		}
 		setValue = defaultValue
@@ -183,10 +245,14 @@ func (source Source) processLeafField(fieldValue reflect.Value, fieldInfo reflec
 			setValue = sourceutil.NormalizeDelimited(setValue, delim)
 		}
 	}
+	event.Default = !ok
 	if err := sourceutil.AssignFromString(source.caster, fieldValue, setValue); err != nil {
-		path := sourceutil.MakePath(prefix, fieldInfo.Name)
 		*errs = append(*errs, setup.NewEnvFieldFailedError(key, setValue, path, err))
+		event.Err = err
+	} else {
+		event.Assigned = true
 	}
+	sourceutil.Observe(source.observer, event)
 	return true
 }
 
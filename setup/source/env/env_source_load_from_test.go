@@ -0,0 +1,24 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/testcommon"
+)
+
+func TestEnvSource_LoadFrom_IgnoresProcessEnvironment(t *testing.T) {
+	t.Setenv("APP_NAME", "process")
+	t.Setenv("APP_PORT", "1")
+
+	source := NewSource("app", ",", setup.ModeOverride)
+	cfg := &testcommon.BasicTypesConfiguration{}
+	err := source.LoadFrom(cfg, map[string]string{"APP_NAME": "map", "APP_DEBUG": "true"})
+	require.NoError(t, err)
+	assert.Equal(t, "map", cfg.Name)
+	assert.Equal(t, 0, cfg.Port)
+	assert.Equal(t, true, cfg.Debug)
+}
@@ -0,0 +1,62 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/testcommon"
+)
+
+func TestEnvSource_Lookup_MapSnapshot(t *testing.T) {
+	t.Parallel()
+	source := NewSourceWithLookup("app", ",", setup.ModeOverride, MapLookup(map[string]string{
+		"APP_NAME":              "snapshot",
+		"APP_PORT":              "8080",
+		"APP_OUTER_INNER_VALUE": "5",
+	}))
+
+	cfg := &testcommon.BasicTypesConfiguration{}
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "snapshot", cfg.Name)
+	assert.Equal(t, 8080, cfg.Port)
+
+	nested := &testcommon.RootNested{}
+	require.NoError(t, source.Load(nested))
+	assert.Equal(t, 5, nested.Outer.Inner.Value)
+}
+
+func TestEnvSource_Lookup_FakeRecordsKeys(t *testing.T) {
+	t.Parallel()
+	var requested []string
+	fake := func(key string) (string, bool) {
+		requested = append(requested, key)
+		if key == "APP_DEBUG" {
+			return "true", true
+		}
+		return "", false
+	}
+	cfg := &testcommon.BasicTypesConfiguration{}
+	require.NoError(t, NewSourceWithLookup("app", ",", setup.ModeOverride, fake).Load(cfg))
+	assert.Equal(t, []string{"APP_NAME", "APP_PORT", "APP_DEBUG"}, requested)
+	assert.Equal(t, true, cfg.Debug)
+}
+
+func TestEnvSource_Lookup_EnvironBlock(t *testing.T) {
+	t.Parallel()
+	environ := []string{"APP_NAME=child=process", "APP_PORT=", "=ignored", "MALFORMED"}
+	cfg := &testcommon.BasicTypesConfiguration{Port: 1}
+	err := NewSourceWithLookup("app", ",", setup.ModeOverride, EnvironLookup(environ)).Load(cfg)
+	require.Error(t, err)
+	assert.Equal(t, "child=process", cfg.Name)
+	assert.Contains(t, err.Error(), "APP_PORT=")
+}
+
+func TestEnvSource_Lookup_WithCaster_And_NilDefault(t *testing.T) {
+	t.Setenv("APP_NAME", "process")
+	cfg := &testcommon.BasicTypesConfiguration{}
+	require.NoError(t, NewSourceWithCasterAndLookup("app", ",", setup.ModeOverride, nil, nil).Load(cfg))
+	assert.Equal(t, "process", cfg.Name)
+}
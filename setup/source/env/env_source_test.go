@@ -139,9 +139,34 @@ func TestLoadStruct_SetsOnlyTaggedFields(t *testing.T) {
 		"APP_NOT_USED":  "9",
 	}
 	var errs []error
-	source.loadStruct(reflect.ValueOf(&r).Elem(), []string{"APP"}, env, setup.ModeOverride, &errs, "")
+	source.loadStruct(reflect.ValueOf(&r).Elem(), []string{"APP"}, MapLookup(env), setup.ModeOverride, &errs, "")
 	require.Empty(t, errs)
 	assert.Equal(t, 123, r.Sub.Value)
 	assert.Equal(t, 0, r.Skip)
 	assert.Equal(t, 0, r.NoTag)
 }
+
+func TestEnvSource_WithFieldObserver_ReportsEveryLeaf(t *testing.T) {
+	type Config struct {
+		Host  string `env:"HOST" envDefault:"localhost"`
+		Level string `env:"LEVEL"`
+		Port  int    `env:"PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+	values := map[string]string{"APP_PORT": "x", "APP_DEBUG": "true"}
+	var events []setup.FieldEvent
+	source := NewSourceWithLookup("app", ",", setup.ModeOverride, func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}).WithFieldObserver(setup.FieldObserverFunc(func(event setup.FieldEvent) {
+		event.Err = nil
+		events = append(events, event)
+	}))
+	require.Error(t, source.Load(&Config{}))
+	assert.Equal(t, []setup.FieldEvent{
+		{Path: "Host", Key: "APP_HOST", Default: true, Eligible: true, Assigned: true},
+		{Path: "Level", Key: "APP_LEVEL"},
+		{Path: "Port", Key: "APP_PORT", Found: true, Eligible: true},
+		{Path: "Debug", Key: "APP_DEBUG", Found: true, Eligible: true, Assigned: true},
+	}, events)
+}
@@ -0,0 +1,174 @@
+package flags
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// argument is a single occurrence of a flag on the command line.
+type argument struct {
+	name     string
+	value    string
+	position int
+}
+
+// flagTable records the flag names the target declares and whether each of
+// them is boolean. Clustering needs the short names to split -vxf or -vp8080.
+// When the target binds positional arguments or WithPositional collects them,
+// strictBool stops boolean flags from taking the following token as their
+// value, so that -v file.txt leaves file.txt positional; --verbose=false still
+// works. When the target declares commands, parsing stops at the first
+// positional argument, which names the command.
+type flagTable struct {
+	long       map[string]bool
+	short      map[string]bool
+	strictBool bool
+	commands   bool
+}
+
+func newFlagTable(specs []flagSpec, strictBool bool) flagTable {
+	table := flagTable{long: make(map[string]bool, len(specs)), short: make(map[string]bool, len(specs)), strictBool: strictBool}
+	for _, spec := range specs {
+		t := spec.field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		table.long[spec.long] = isBoolFlag(t)
+		if spec.short != "" {
+			table.short[spec.short] = isBoolFlag(t)
+		}
+	}
+	return table
+}
+
+// parseArguments groups flag occurrences by name and returns the remaining
+// positional arguments. Supported forms are --name=value, --name value,
+// --no-name, -n=value, -n value, clustered boolean short flags (-vxf), an
+// attached short value (-p8080 or -vp8080) and the -- terminator. For targets
+// with commands, the arguments from the command name onwards are returned
+// unparsed as tail.
+func parseArguments(args []string, table flagTable) (map[string][]argument, []string, []string) {
+	result := make(map[string][]argument)
+	var positional []string
+	add := func(name string, value string, position int) {
+		result[name] = append(result[name], argument{name: name, value: value, position: position})
+	}
+	takesNext := func(next int, isBool bool) bool {
+		if table.strictBool && isBool {
+			return false
+		}
+		return next < len(args) && !strings.HasPrefix(args[next], "-")
+	}
+	i := 0
+	for i < len(args) {
+		token := args[i]
+		if token == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if strings.HasPrefix(token, "--") {
+			name := token[2:]
+			if eq := strings.IndexByte(name, '='); eq >= 0 {
+				key := name[:eq]
+				value := name[eq+1:]
+				if strings.HasPrefix(key, "no-") {
+					add(key[3:], "false", i)
+				} else {
+					add(key, value, i)
+				}
+				i++
+				continue
+			}
+			if strings.HasPrefix(name, "no-") {
+				add(name[3:], "false", i)
+				i++
+				continue
+			}
+			if takesNext(i+1, table.long[name]) {
+				add(name, args[i+1], i)
+				i += 2
+				continue
+			}
+			add(name, "", i)
+			i++
+			continue
+		}
+		if strings.HasPrefix(token, "-") && len(token) >= 2 {
+			name := token[1:]
+			if eq := strings.IndexByte(name, '='); eq >= 0 {
+				add(name[:eq], name[eq+1:], i)
+				i++
+				continue
+			}
+			if _, known := table.short[name]; !known {
+				if cluster, consumed, ok := table.splitCluster(name, args, i); ok {
+					for _, occurrence := range cluster {
+						add(occurrence.name, occurrence.value, occurrence.position)
+					}
+					i += consumed
+					continue
+				}
+			}
+			if takesNext(i+1, table.short[name]) {
+				add(name, args[i+1], i)
+				i += 2
+				continue
+			}
+			add(name, "", i)
+			i++
+			continue
+		}
+		if table.commands {
+			return result, positional, args[i:]
+		}
+		positional = append(positional, token)
+		i++
+	}
+	return result, positional, nil
+}
+
+// splitCluster interprets name as a run of single-character short flags. Every
+// flag but the last must be boolean; a non-boolean flag takes the rest of the
+// token as its value, or the following token when nothing is left. It reports
+// false when any character is not a declared short name, so that unknown
+// tokens keep their single-flag meaning.
+func (table flagTable) splitCluster(name string, args []string, position int) ([]argument, int, bool) {
+	var cluster []argument
+	for offset := 0; offset < len(name); {
+		r, size := utf8.DecodeRuneInString(name[offset:])
+		short := string(r)
+		isBool, known := table.short[short]
+		if !known {
+			return nil, 0, false
+		}
+		offset += size
+		if isBool {
+			cluster = append(cluster, argument{name: short, position: position})
+			continue
+		}
+		if rest := name[offset:]; rest != "" {
+			return append(cluster, argument{name: short, value: rest, position: position}), 1, true
+		}
+		if position+1 < len(args) && !strings.HasPrefix(args[position+1], "-") {
+			return append(cluster, argument{name: short, value: args[position+1], position: position}), 2, true
+		}
+		return append(cluster, argument{name: short, position: position}), 1, true
+	}
+	return cluster, 1, true
+}
+
+// lookupOccurrences returns every occurrence of the long or short name in the
+// order they appeared on the command line.
+func lookupOccurrences(args map[string][]argument, long string, short string) []argument {
+	occurrences := args[long]
+	if short == "" || short == long || len(args[short]) == 0 {
+		return occurrences
+	}
+	merged := make([]argument, 0, len(occurrences)+len(args[short]))
+	merged = append(merged, occurrences...)
+	merged = append(merged, args[short]...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].position < merged[j].position })
+	return merged
+}
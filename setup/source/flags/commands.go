@@ -0,0 +1,138 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+// commandSpec is a field tagged with cmd:"name". Its struct holds the flags
+// and positional arguments accepted after the command name.
+type commandSpec struct {
+	name  string
+	usage string
+	field reflect.StructField
+}
+
+// commandLevel is the parsed command line of one struct: the root target or a
+// selected command. field is nil for the root.
+type commandLevel struct {
+	field      *reflect.StructField
+	args       map[string][]argument
+	positional []string
+}
+
+// collectCommands returns the commands declared directly on structType.
+func collectCommands(structType reflect.Type) ([]commandSpec, error) {
+	var commands []commandSpec
+	seen := make(map[string]string)
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" || !sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
+		name := fieldInfo.Tag.Get("cmd")
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, setup.NewInvalidTargetError(fmt.Sprintf("command field %s must be a struct or pointer to struct", fieldInfo.Name))
+		}
+		if other, ok := seen[name]; ok {
+			return nil, setup.NewInvalidTargetError(fmt.Sprintf("fields %s and %s both use cmd:%q", other, fieldInfo.Name, name))
+		}
+		seen[name] = fieldInfo.Name
+		usage := fieldInfo.Tag.Get("cmdUsage")
+		if usage == "" {
+			usage = fieldInfo.Tag.Get("desc")
+		}
+		commands = append(commands, commandSpec{name: name, usage: usage, field: fieldInfo})
+	}
+	return commands, nil
+}
+
+func commandNames(commands []commandSpec) []string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.name)
+	}
+	return names
+}
+
+// parseCommandLine splits args into one level per struct, starting with the
+// root target and following the selected command of each level. Flags are
+// parsed against the struct of their level only, so global flags go before the
+// command name and command flags after it. It returns the levels and the
+// selected command names.
+func (source Source) parseCommandLine(structType reflect.Type, args []string) ([]commandLevel, []string, error) {
+	var levels []commandLevel
+	var chosen []string
+	var field *reflect.StructField
+	program := source.programName()
+	for {
+		specs := source.collectFlagSpecs(structType, "", "", nil)
+		commands, err := collectCommands(structType)
+		if err != nil {
+			return nil, nil, err
+		}
+		table := newFlagTable(specs, source.positional != nil || declaresArgs(structType) || len(commands) > 0)
+		table.commands = len(commands) > 0
+		argsMap, positional, tail := parseArguments(args, table)
+		if source.completion && len(levels) == 0 {
+			if completionErr := source.completionRequested(structType, specs, argsMap); completionErr != nil {
+				return nil, nil, completionErr
+			}
+		}
+		if source.help {
+			if helpErr := helpRequested(program, specs, commands, argsMap); helpErr != nil {
+				return nil, nil, helpErr
+			}
+		}
+		levels = append(levels, commandLevel{field: field, args: argsMap, positional: positional})
+		if len(commands) == 0 {
+			return levels, chosen, nil
+		}
+		if len(tail) == 0 {
+			if len(positional) > 0 {
+				return nil, nil, setup.NewCommandUnknownError(positional[0], commandNames(commands))
+			}
+			return nil, nil, setup.NewCommandMissingError(commandNames(commands))
+		}
+		selected := -1
+		for i, command := range commands {
+			if command.name == tail[0] {
+				selected = i
+				break
+			}
+		}
+		if selected < 0 {
+			return nil, nil, setup.NewCommandUnknownError(tail[0], commandNames(commands))
+		}
+		command := commands[selected]
+		chosen = append(chosen, command.name)
+		program = strings.TrimSpace(program + " " + command.name)
+		field = &command.field
+		structType = command.field.Type
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		args = tail[1:]
+	}
+}
+
+// commandValue returns the struct of the selected command inside parent,
+// allocating a nil pointer.
+func commandValue(parent reflect.Value, field reflect.StructField) reflect.Value {
+	fieldValue := parent.FieldByIndex(field.Index)
+	if fieldValue.Kind() != reflect.Ptr {
+		return fieldValue
+	}
+	if fieldValue.IsNil() {
+		fieldValue.Set(reflect.New(field.Type.Elem()))
+	}
+	return fieldValue.Elem()
+}
@@ -0,0 +1,180 @@
+package flags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+type ServeCommand struct {
+	Host string `flag:"host" flagDefault:"localhost"`
+	Port int    `flag:"port" flagShort:"p" flagUsage:"Listen port"`
+}
+
+type MigrateUpCommand struct {
+	Steps int `arg:"0" argDefault:"1"`
+}
+
+type MigrateCommand struct {
+	Up     *MigrateUpCommand `cmd:"up"`
+	DryRun bool              `flag:"dry-run"`
+}
+
+type CommandsConfig struct {
+	Serve   *ServeCommand  `cmd:"serve" cmdUsage:"Run the HTTP server"`
+	Migrate MigrateCommand `cmd:"migrate" desc:"Manage the schema"`
+	Config  string         `flag:"config" flagShort:"c" flagUsage:"Config file"`
+	Verbose bool           `flag:"verbose" flagShort:"v"`
+}
+
+func TestFlagsSource_Commands_GlobalFlagsBeforeCommand(t *testing.T) {
+	t.Parallel()
+	cfg := &CommandsConfig{}
+	var command string
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"-v", "--config", "app.yaml", "serve", "-p", "8080"}),
+		WithCommand(&command),
+	)
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "serve", command)
+	assert.True(t, cfg.Verbose)
+	assert.Equal(t, "app.yaml", cfg.Config)
+	require.NotNil(t, cfg.Serve)
+	assert.Equal(t, 8080, cfg.Serve.Port)
+	assert.Equal(t, "localhost", cfg.Serve.Host)
+	assert.False(t, cfg.Migrate.DryRun)
+}
+
+func TestFlagsSource_Commands_FlagsAreScopedToTheirLevel(t *testing.T) {
+	t.Parallel()
+	cfg := &CommandsConfig{}
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"serve", "--verbose", "--port", "1"}).Load(cfg)
+	require.NoError(t, err)
+	assert.False(t, cfg.Verbose)
+	assert.Equal(t, 1, cfg.Serve.Port)
+
+	cfg = &CommandsConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"--port", "1", "serve"}).Load(cfg))
+	assert.Equal(t, 0, cfg.Serve.Port)
+}
+
+func TestFlagsSource_Commands_Nested(t *testing.T) {
+	t.Parallel()
+	cfg := &CommandsConfig{}
+	var command string
+	var positional []string
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"migrate", "--dry-run", "up", "3"}),
+		WithCommand(&command),
+		WithPositional(&positional),
+	)
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "migrate up", command)
+	assert.Equal(t, []string{"3"}, positional)
+	assert.True(t, cfg.Migrate.DryRun)
+	require.NotNil(t, cfg.Migrate.Up)
+	assert.Equal(t, 3, cfg.Migrate.Up.Steps)
+	assert.Nil(t, cfg.Serve)
+}
+
+func TestFlagsSource_Commands_ExplainLeavesSideChannelsAlone(t *testing.T) {
+	t.Parallel()
+	command := "unchanged"
+	positional := []string{"unchanged"}
+	loader := setup.NewLoader(NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"migrate", "--dry-run", "up", "3"}),
+		WithCommand(&command),
+		WithPositional(&positional),
+	))
+	cfg := &CommandsConfig{}
+	explanation, err := loader.Explain(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "unchanged", command)
+	assert.Equal(t, []string{"unchanged"}, positional)
+	assert.False(t, cfg.Migrate.DryRun)
+	field, ok := explanation.Field("Migrate.DryRun")
+	require.True(t, ok)
+	winner, ok := field.Winner()
+	require.True(t, ok)
+	assert.Equal(t, true, winner.Value)
+
+	require.NoError(t, loader.Load(cfg))
+	assert.Equal(t, "migrate up", command)
+	assert.Equal(t, []string{"3"}, positional)
+}
+
+func TestFlagsSource_Commands_MissingAndUnknown(t *testing.T) {
+	t.Parallel()
+	cfg := &CommandsConfig{}
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"-v"}).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrCommandMissing))
+	assert.False(t, cfg.Verbose)
+
+	err = NewSourceWithArgs(setup.ModeOverride, []string{"deploy"}).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrCommandUnknown))
+	var commandErr *setup.CommandError
+	require.True(t, errors.As(err, &commandErr))
+	assert.Equal(t, "deploy", commandErr.Name)
+	assert.Equal(t, []string{"serve", "migrate"}, commandErr.Commands)
+	assert.EqualError(t, commandErr, `"deploy" is not one of: serve, migrate`)
+
+	err = NewSourceWithArgs(setup.ModeOverride, []string{"migrate", "down"}).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrCommandUnknown))
+}
+
+func TestFlagsSource_Commands_FieldErrorsUseCommandPath(t *testing.T) {
+	t.Parallel()
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"serve", "--port", "x"}).Load(&CommandsConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "flags port=x field Serve.Port")
+}
+
+func TestFlagsSource_Commands_InvalidTargets(t *testing.T) {
+	t.Parallel()
+	type ScalarCommand struct {
+		Run string `cmd:"run"`
+	}
+	type DuplicateCommand struct {
+		A struct{} `cmd:"run"`
+		B struct{} `cmd:"run"`
+	}
+	for _, target := range []any{&ScalarCommand{}, &DuplicateCommand{}} {
+		err := NewSourceWithArgs(setup.ModeOverride, []string{"run"}).Load(target)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+	}
+}
+
+func TestFlagsSource_Commands_Help(t *testing.T) {
+	t.Parallel()
+	source := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"-v", "serve", "--help"}), WithHelp("svc"))
+	err := source.Load(&CommandsConfig{})
+	var helpErr *setup.HelpRequestedError
+	require.True(t, errors.As(err, &helpErr))
+	assert.Equal(t, `Usage: svc serve [options]
+
+Options:
+      --host string  (default "localhost")
+  -p, --port int     Listen port
+`, helpErr.Usage)
+
+	usage, err := NewSourceWithOptions(setup.ModeOverride, WithHelp("svc")).Usage(&CommandsConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, `Usage: svc [options] <command>
+
+Commands:
+  serve    Run the HTTP server
+  migrate  Manage the schema
+
+Options:
+  -c, --config string  Config file
+  -v, --verbose
+`, usage)
+}
@@ -0,0 +1,338 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+const (
+	pathFile = "file"
+	pathDir  = "dir"
+)
+
+// completionLevel is the root target or one command, identified by the command
+// names leading to it joined with a space. The root has an empty key.
+type completionLevel struct {
+	key      string
+	flags    []flagSpec
+	commands []commandSpec
+}
+
+// Completion renders a completion script for the given shell: bash, zsh or
+// fish. Flags come from the flag and flagShort tags, including nested structs
+// and commands. A flagEnum tag lists the values offered for a flag, and
+// flagPath:"file" or flagPath:"dir" switches to file or directory completion.
+func Completion(shell string, program string, cfg any) (string, error) {
+	source := NewSourceWithOptions(setup.ModeOverride)
+	source.program = program
+	return source.Completion(shell, cfg)
+}
+
+// Completion renders a completion script for cfg using the program name and
+// segment separator configured on the source.
+func (source Source) Completion(shell string, cfg any) (string, error) {
+	structType, err := targetStructType(cfg)
+	if err != nil {
+		return "", err
+	}
+	return source.renderCompletion(shell, structType)
+}
+
+func (source Source) renderCompletion(shell string, structType reflect.Type) (string, error) {
+	levels, err := source.collectCompletionLevels(structType, "", nil)
+	if err != nil {
+		return "", err
+	}
+	program := source.programName()
+	switch shell {
+	case "bash":
+		return renderBashCompletion(program, levels), nil
+	case "zsh":
+		return renderZshCompletion(program, levels), nil
+	case "fish":
+		return renderFishCompletion(program, levels), nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected bash, zsh or fish", setup.ErrShellUnsupported, shell)
+	}
+}
+
+// completionRequested returns a CompletionRequestedError when the hidden
+// --completion flag is present and the target does not claim that name.
+func (source Source) completionRequested(structType reflect.Type, specs []flagSpec, args map[string][]argument) error {
+	occurrences, ok := args["completion"]
+	if !ok {
+		return nil
+	}
+	for _, spec := range specs {
+		if spec.long == "completion" {
+			return nil
+		}
+	}
+	shell := occurrences[len(occurrences)-1].value
+	script, err := source.renderCompletion(shell, structType)
+	if err != nil {
+		return err
+	}
+	return setup.NewCompletionRequestedError(shell, script)
+}
+
+func (source Source) collectCompletionLevels(structType reflect.Type, key string, levels []completionLevel) ([]completionLevel, error) {
+	specs := source.collectFlagSpecs(structType, "", "", nil)
+	for _, spec := range specs {
+		if spec.pathKind != "" && spec.pathKind != pathFile && spec.pathKind != pathDir {
+			return nil, setup.NewInvalidTargetError(fmt.Sprintf("field %s has invalid flagPath tag %q", spec.field.Name, spec.pathKind))
+		}
+	}
+	commands, err := collectCommands(structType)
+	if err != nil {
+		return nil, err
+	}
+	levels = append(levels, completionLevel{key: key, flags: specs, commands: commands})
+	for _, command := range commands {
+		commandType := command.field.Type
+		if commandType.Kind() == reflect.Ptr {
+			commandType = commandType.Elem()
+		}
+		levels, err = source.collectCompletionLevels(commandType, strings.TrimSpace(key+" "+command.name), levels)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return levels, nil
+}
+
+// takesValue reports whether the word after the flag is its value.
+func (spec flagSpec) takesValue() bool {
+	t := spec.field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return !isBoolFlag(t)
+}
+
+// flagWords returns the spellings of the flag as typed on the command line.
+func (spec flagSpec) flagWords() []string {
+	words := []string{"--" + spec.long}
+	if spec.short != "" {
+		words = append(words, "-"+spec.short)
+	}
+	return words
+}
+
+// levelWords returns every flag spelling and command name offered at a level.
+func (level completionLevel) levelWords() []string {
+	var words []string
+	for _, spec := range level.flags {
+		words = append(words, spec.flagWords()...)
+	}
+	for _, command := range level.commands {
+		words = append(words, command.name)
+	}
+	return words
+}
+
+// functionName turns a program name into a shell identifier.
+func functionName(program string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, program)
+}
+
+// shellQuote quotes a word for bash and zsh.
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes a word for fish.
+func fishQuote(word string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(word) + "'"
+}
+
+// casePatterns joins the given words into a case pattern prefixed with the
+// command key of their level.
+func casePatterns(key string, words []string) string {
+	patterns := make([]string, 0, len(words))
+	for _, word := range words {
+		patterns = append(patterns, shellQuote(key+"|"+word))
+	}
+	return strings.Join(patterns, "|")
+}
+
+// writeCommandTracking writes the case statement that follows command names
+// through the words typed so far and keeps the selected path in cmdpath.
+func writeCommandTracking(builder *strings.Builder, levels []completionLevel, indent string) {
+	builder.WriteString(indent + "case \"${cmdpath}|${word}\" in\n")
+	for _, level := range levels {
+		for _, command := range level.commands {
+			next := strings.TrimSpace(level.key + " " + command.name)
+			builder.WriteString(indent + "    " + shellQuote(level.key+"|"+command.name) + ") cmdpath=" + shellQuote(next) + " ;;\n")
+		}
+	}
+	builder.WriteString(indent + "esac\n")
+}
+
+func renderBashCompletion(program string, levels []completionLevel) string {
+	name := functionName(program)
+	var builder strings.Builder
+	builder.WriteString("# bash completion for " + program + "\n")
+	builder.WriteString(name + "() {\n")
+	builder.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	builder.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	builder.WriteString("    local cmdpath=\"\" word i\n")
+	builder.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	builder.WriteString("        word=\"${COMP_WORDS[i]}\"\n")
+	writeCommandTracking(&builder, levels, "        ")
+	builder.WriteString("    done\n")
+	builder.WriteString("    case \"${cmdpath}|${prev}\" in\n")
+	for _, level := range levels {
+		for _, spec := range level.flags {
+			if !spec.takesValue() {
+				continue
+			}
+			builder.WriteString("        " + casePatterns(level.key, spec.flagWords()) + ")\n")
+			switch {
+			case len(spec.values) > 0:
+				builder.WriteString("            COMPREPLY=($(compgen -W " + shellQuote(strings.Join(spec.values, " ")) + " -- \"$cur\"))\n")
+			case spec.pathKind == pathDir:
+				builder.WriteString("            COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+			case spec.pathKind == pathFile:
+				builder.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+			default:
+				builder.WriteString("            COMPREPLY=()\n")
+			}
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+		}
+	}
+	builder.WriteString("    esac\n")
+	builder.WriteString("    case \"$cmdpath\" in\n")
+	for _, level := range levels {
+		builder.WriteString("        " + shellQuote(level.key) + ")\n")
+		builder.WriteString("            COMPREPLY=($(compgen -W " + shellQuote(strings.Join(level.levelWords(), " ")) + " -- \"$cur\"))\n")
+		builder.WriteString("            ;;\n")
+	}
+	builder.WriteString("    esac\n")
+	builder.WriteString("}\n")
+	builder.WriteString("complete -F " + name + " " + program + "\n")
+	return builder.String()
+}
+
+func renderZshCompletion(program string, levels []completionLevel) string {
+	name := functionName(program)
+	var builder strings.Builder
+	builder.WriteString("#compdef " + program + "\n\n")
+	builder.WriteString(name + "() {\n")
+	builder.WriteString("    local prev=\"${words[CURRENT-1]}\"\n")
+	builder.WriteString("    local cmdpath=\"\" word i\n")
+	builder.WriteString("    for ((i = 2; i < CURRENT; i++)); do\n")
+	builder.WriteString("        word=\"${words[i]}\"\n")
+	writeCommandTracking(&builder, levels, "        ")
+	builder.WriteString("    done\n")
+	builder.WriteString("    case \"${cmdpath}|${prev}\" in\n")
+	for _, level := range levels {
+		for _, spec := range level.flags {
+			if !spec.takesValue() {
+				continue
+			}
+			builder.WriteString("        " + casePatterns(level.key, spec.flagWords()) + ")\n")
+			switch {
+			case len(spec.values) > 0:
+				quoted := make([]string, 0, len(spec.values))
+				for _, value := range spec.values {
+					quoted = append(quoted, shellQuote(value))
+				}
+				builder.WriteString("            compadd -- " + strings.Join(quoted, " ") + "\n")
+			case spec.pathKind == pathDir:
+				builder.WriteString("            _files -/\n")
+			case spec.pathKind == pathFile:
+				builder.WriteString("            _files\n")
+			}
+			builder.WriteString("            return\n")
+			builder.WriteString("            ;;\n")
+		}
+	}
+	builder.WriteString("    esac\n")
+	builder.WriteString("    case \"$cmdpath\" in\n")
+	for _, level := range levels {
+		quoted := make([]string, 0)
+		for _, word := range level.levelWords() {
+			quoted = append(quoted, shellQuote(word))
+		}
+		builder.WriteString("        " + shellQuote(level.key) + ")\n")
+		builder.WriteString("            compadd -- " + strings.Join(quoted, " ") + "\n")
+		builder.WriteString("            ;;\n")
+	}
+	builder.WriteString("    esac\n")
+	builder.WriteString("}\n\n")
+	builder.WriteString("if [ \"$funcstack[1]\" = " + shellQuote(name) + " ]; then\n")
+	builder.WriteString("    " + name + " \"$@\"\n")
+	builder.WriteString("else\n")
+	builder.WriteString("    compdef " + name + " " + program + "\n")
+	builder.WriteString("fi\n")
+	return builder.String()
+}
+
+func renderFishCompletion(program string, levels []completionLevel) string {
+	name := "_" + functionName(program) + "_using"
+	var builder strings.Builder
+	builder.WriteString("# fish completion for " + program + "\n")
+	builder.WriteString("function " + name + "\n")
+	builder.WriteString("    set -l cmdpath ''\n")
+	builder.WriteString("    for word in (commandline -opc)[2..-1]\n")
+	builder.WriteString("        switch \"$cmdpath|$word\"\n")
+	for _, level := range levels {
+		for _, command := range level.commands {
+			next := strings.TrimSpace(level.key + " " + command.name)
+			builder.WriteString("            case " + fishQuote(level.key+"|"+command.name) + "\n")
+			builder.WriteString("                set cmdpath " + fishQuote(next) + "\n")
+		}
+	}
+	builder.WriteString("        end\n")
+	builder.WriteString("    end\n")
+	builder.WriteString("    test \"$cmdpath\" = \"$argv[1]\"\n")
+	builder.WriteString("end\n\n")
+	builder.WriteString("complete -c " + program + " -f\n")
+	for _, level := range levels {
+		condition := " -n " + fishQuote(name+" "+fishQuote(level.key))
+		for _, command := range level.commands {
+			builder.WriteString("complete -c " + program + condition + " -a " + fishQuote(command.name))
+			if command.usage != "" {
+				builder.WriteString(" -d " + fishQuote(command.usage))
+			}
+			builder.WriteString("\n")
+		}
+		for _, spec := range level.flags {
+			builder.WriteString("complete -c " + program + condition + " -l " + fishQuote(spec.long))
+			if spec.short != "" {
+				if len([]rune(spec.short)) == 1 {
+					builder.WriteString(" -s " + fishQuote(spec.short))
+				} else {
+					builder.WriteString(" -o " + fishQuote(spec.short))
+				}
+			}
+			if spec.takesValue() {
+				switch {
+				case len(spec.values) > 0:
+					builder.WriteString(" -x -a " + fishQuote(strings.Join(spec.values, " ")))
+				case spec.pathKind == pathDir:
+					builder.WriteString(" -x -a '(__fish_complete_directories)'")
+				case spec.pathKind == pathFile:
+					builder.WriteString(" -r -F")
+				default:
+					builder.WriteString(" -x")
+				}
+			}
+			if spec.usage != "" {
+				builder.WriteString(" -d " + fishQuote(spec.usage))
+			}
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
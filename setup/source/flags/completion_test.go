@@ -0,0 +1,167 @@
+package flags
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+type CompletionServe struct {
+	Root string `flag:"root" flagPath:"dir"`
+	Port int    `flag:"port" flagShort:"p"`
+}
+
+type CompletionConfig struct {
+	Serve  *CompletionServe `cmd:"serve" cmdUsage:"Run the server"`
+	Output struct {
+		Format string `flag:"format" flagShort:"f" flagEnum:"json|yaml|text" flagUsage:"Output format"`
+	} `flagSegment:"out"`
+	Config  string `flag:"config" flagShort:"c" flagPath:"file"`
+	Verbose bool   `flag:"verbose" flagShort:"v"`
+}
+
+func TestCompletion_Bash(t *testing.T) {
+	t.Parallel()
+	script, err := Completion("bash", "svc", &CompletionConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, `# bash completion for svc
+_svc() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local cmdpath="" word i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        word="${COMP_WORDS[i]}"
+        case "${cmdpath}|${word}" in
+            '|serve') cmdpath='serve' ;;
+        esac
+    done
+    case "${cmdpath}|${prev}" in
+        '|--out.format'|'|-f')
+            COMPREPLY=($(compgen -W 'json yaml text' -- "$cur"))
+            return
+            ;;
+        '|--config'|'|-c')
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return
+            ;;
+        'serve|--root')
+            COMPREPLY=($(compgen -d -- "$cur"))
+            return
+            ;;
+        'serve|--port'|'serve|-p')
+            COMPREPLY=()
+            return
+            ;;
+    esac
+    case "$cmdpath" in
+        '')
+            COMPREPLY=($(compgen -W '--out.format -f --config -c --verbose -v serve' -- "$cur"))
+            ;;
+        'serve')
+            COMPREPLY=($(compgen -W '--root --port -p' -- "$cur"))
+            ;;
+    esac
+}
+complete -F _svc svc
+`, script)
+}
+
+func TestCompletion_BashScriptRuns(t *testing.T) {
+	t.Parallel()
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	script, err := Completion("bash", "svc", &CompletionConfig{})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "svc.bash"), []byte(script), 0o600))
+	complete := func(words ...string) string {
+		driver := `source svc.bash
+COMP_WORDS=("$@"); COMP_CWORD=$(($# - 1))
+_svc
+printf '%s\n' "${COMPREPLY[@]}"`
+		command := exec.Command(bash, append([]string{"-c", driver, "bash"}, words...)...)
+		command.Dir = dir
+		output, err := command.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	assert.Equal(t, "json", complete("svc", "-f", "j"))
+	assert.Equal(t, "serve", complete("svc", "-v", "se"))
+	assert.Equal(t, "--port", complete("svc", "serve", "--po"))
+	assert.Equal(t, "--verbose", complete("svc", "--verb"))
+}
+
+func TestCompletion_Zsh(t *testing.T) {
+	t.Parallel()
+	script, err := Completion("zsh", "svc", &CompletionConfig{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(script, "#compdef svc\n"))
+	assert.Contains(t, script, "        '|--out.format'|'|-f')\n            compadd -- 'json' 'yaml' 'text'\n")
+	assert.Contains(t, script, "        '|--config'|'|-c')\n            _files\n")
+	assert.Contains(t, script, "        'serve|--root')\n            _files -/\n")
+	assert.Contains(t, script, "            compadd -- '--root' '--port' '-p'\n")
+	assert.Contains(t, script, "    compdef _svc svc\n")
+}
+
+func TestCompletion_Fish(t *testing.T) {
+	t.Parallel()
+	script, err := Completion("fish", "svc", &CompletionConfig{})
+	require.NoError(t, err)
+	assert.Contains(t, script, "            case '|serve'\n                set cmdpath 'serve'\n")
+	assert.Contains(t, script, `complete -c svc -n '__svc_using \'\'' -a 'serve' -d 'Run the server'`)
+	assert.Contains(t, script, `complete -c svc -n '__svc_using \'\'' -l 'out.format' -s 'f' -x -a 'json yaml text' -d 'Output format'`)
+	assert.Contains(t, script, `complete -c svc -n '__svc_using \'\'' -l 'config' -s 'c' -r -F`)
+	assert.Contains(t, script, `complete -c svc -n '__svc_using \'\'' -l 'verbose' -s 'v'`+"\n")
+	assert.Contains(t, script, `complete -c svc -n '__svc_using \'serve\'' -l 'root' -x -a '(__fish_complete_directories)'`)
+}
+
+func TestCompletion_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := Completion("powershell", "svc", &CompletionConfig{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrShellUnsupported))
+
+	type BadPath struct {
+		Config string `flag:"config" flagPath:"socket"`
+	}
+	_, err = Completion("bash", "svc", &BadPath{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+}
+
+func TestFlagsSource_CompletionFlag(t *testing.T) {
+	t.Parallel()
+	cfg := &CompletionConfig{}
+	source := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"-v", "--completion", "fish"}), WithCompletion("svc"))
+	err := source.Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrCompletionRequested))
+	var completionErr *setup.CompletionRequestedError
+	require.True(t, errors.As(err, &completionErr))
+	assert.Equal(t, "fish", completionErr.Shell)
+	expected, err := Completion("fish", "svc", &CompletionConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, expected, completionErr.Script)
+	assert.False(t, cfg.Verbose)
+
+	err = NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"--completion", "tcsh"}), WithCompletion("svc")).Load(cfg)
+	assert.True(t, errors.Is(err, setup.ErrShellUnsupported))
+
+	err = NewSourceWithArgs(setup.ModeOverride, []string{"--completion", "bash", "serve"}).Load(cfg)
+	require.NoError(t, err)
+
+	usage, err := NewSourceWithOptions(setup.ModeOverride, WithCompletion("svc")).Usage(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, usage, "completion")
+	assert.Contains(t, usage, `Output format (one of: json, yaml, text)`)
+}
@@ -12,27 +12,155 @@ import (
 )
 
 type Source struct {
-	caster    setup.TypeCaster
-	delimiter string
-	mode      setup.LoadMode
+	caster       setup.TypeCaster
+	observer     setup.FieldObserver
+	positional   *[]string
+	command      *string
+	delimiter    string
+	separator    string
+	program      string
+	args         []string
+	mode         setup.LoadMode
+	repeat       RepeatPolicy
+	explicitArgs bool
+	help         bool
+	completion   bool
+	dryRun       bool
+}
+
+// RepeatPolicy controls what happens when a flag bound to a scalar field is
+// given more than once. Slice and int array fields always accumulate.
+type RepeatPolicy int
+
+const (
+	RepeatLastWins RepeatPolicy = iota
+	RepeatError
+)
+
+// Option configures a Source created by NewSourceWithOptions.
+type Option func(*Source)
+
+// WithArgs makes the source parse the given arguments instead of os.Args[1:].
+// The slice must not include the program name.
+func WithArgs(args []string) Option {
+	return func(source *Source) {
+		source.args = args
+		source.explicitArgs = true
+	}
+}
+
+// WithHelp makes Load detect --help and -h. When either is present and the
+// target does not use that name for its own flag, Load assigns nothing and returns
+// a HelpRequestedError carrying the rendered usage text. An empty program name
+// defaults to the base name of os.Args[0].
+func WithHelp(program string) Option {
+	return func(source *Source) {
+		source.help = true
+		if program != "" {
+			source.program = program
+		}
+	}
+}
+
+// WithCompletion makes Load handle a hidden --completion <shell> flag. When it
+// is present and the target does not use that name for its own flag, Load
+// assigns nothing and returns a CompletionRequestedError carrying the bash, zsh
+// or fish script. An empty program name keeps the one set by WithHelp, or
+// defaults to the base name of os.Args[0].
+func WithCompletion(program string) Option {
+	return func(source *Source) {
+		source.completion = true
+		if program != "" {
+			source.program = program
+		}
+	}
+}
+
+// WithSegmentSeparator sets the separator placed between flagSegment names and
+// the leaf flag name, for example "." for --db.host or "-" for --db-host.
+func WithSegmentSeparator(separator string) Option {
+	return func(source *Source) {
+		source.separator = separator
+	}
+}
+
+// WithPositional makes Load store the positional arguments in target: tokens
+// that are neither flags nor flag values, and everything after the "--"
+// terminator, in command-line order. Boolean flags then never take the next
+// token as their value, so -v file.txt keeps file.txt positional; use
+// --verbose=false to pass a value.
+func WithPositional(target *[]string) Option {
+	return func(source *Source) {
+		source.positional = target
+	}
+}
+
+// WithCommand makes Load store the selected command in target. Nested
+// commands are joined with a space, for example "db migrate". Targets without
+// cmd fields store an empty string.
+func WithCommand(target *string) Option {
+	return func(source *Source) {
+		source.command = target
+	}
+}
+
+// WithRepeatPolicy sets how repeated scalar flags are handled. The default is
+// RepeatLastWins.
+func WithRepeatPolicy(policy RepeatPolicy) Option {
+	return func(source *Source) {
+		source.repeat = policy
+	}
+}
+
+// WithCaster sets the TypeCaster used for string-to-type conversion.
+func WithCaster(caster setup.TypeCaster) Option {
+	return func(source *Source) {
+		if caster != nil {
+			source.caster = caster
+		}
+	}
+}
+
+// WithDelimiter sets the default delimiter for slice and array fields.
+func WithDelimiter(delimiter string) Option {
+	return func(source *Source) {
+		if delimiter != "" {
+			source.delimiter = delimiter
+		}
+	}
 }
 
 func NewSource(mode setup.LoadMode) *Source {
-	return &Source{caster: setup.NewTypeCaster(), mode: sourceutil.DefaultMode(mode), delimiter: ","}
+	return &Source{caster: setup.NewTypeCaster(), mode: sourceutil.DefaultMode(mode), delimiter: ",", separator: "."}
+}
+
+func NewSourceWithOptions(mode setup.LoadMode, options ...Option) *Source {
+	source := NewSource(mode)
+	for _, option := range options {
+		if option != nil {
+			option(source)
+		}
+	}
+	return source
+}
+
+// NewSourceWithArgs creates a source that parses the given arguments instead of os.Args[1:].
+func NewSourceWithArgs(mode setup.LoadMode, args []string) *Source {
+	return NewSourceWithOptions(mode, WithArgs(args))
 }
 
 func NewSourceWithCaster(mode setup.LoadMode, caster setup.TypeCaster) *Source {
 	if caster == nil {
 		caster = setup.NewTypeCaster()
 	}
-	return &Source{caster: caster, mode: sourceutil.DefaultMode(mode), delimiter: ","}
+	return &Source{caster: caster, mode: sourceutil.DefaultMode(mode), delimiter: ",", separator: "."}
 }
 
 func NewSourceWithDelimiter(mode setup.LoadMode, delimiter string) *Source {
 	if delimiter == "" {
 		delimiter = ","
 	}
-	return &Source{caster: setup.NewTypeCaster(), mode: sourceutil.DefaultMode(mode), delimiter: delimiter}
+	return &Source{caster: setup.NewTypeCaster(), mode: sourceutil.DefaultMode(mode), delimiter: delimiter, separator: "."}
 }
 
 func NewSourceWithCasterAndDelimiter(mode setup.LoadMode, delimiter string, caster setup.TypeCaster) *Source {
@@ -42,7 +170,22 @@ func NewSourceWithCasterAndDelimiter(mode setup.LoadMode, delimiter string, cast
 	if caster == nil {
 		caster = setup.NewTypeCaster()
 	}
-	return &Source{caster: caster, mode: sourceutil.DefaultMode(mode), delimiter: delimiter}
+	return &Source{caster: caster, mode: sourceutil.DefaultMode(mode), delimiter: delimiter, separator: "."}
+}
+
+// WithFieldObserver returns a copy of the source that reports every flag and
+// positional argument field to observer.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
+// WithoutSideEffects returns a copy of the source that parses and assigns
+// the same way but does not store positional arguments or the selected
+// command through WithPositional and WithCommand. Loader.Explain uses it.
+func (source Source) WithoutSideEffects() setup.Source {
+	source.dryRun = true
+	return &source
 }
 
 func (source Source) Load(cfg any) error {
@@ -51,168 +194,179 @@ func (source Source) Load(cfg any) error {
 		return err
 	}
 
-	argsMap := parseArguments(os.Args[1:])
+	levels, chosen, err := source.parseCommandLine(elem.Type(), source.arguments())
+	if err != nil {
+		return err
+	}
+	values := make([]reflect.Value, len(levels))
+	prefixes := make([]string, len(levels))
+	bound := make([]argFields, len(levels))
+	value := elem
+	prefix := ""
+	for i, level := range levels {
+		if level.field != nil {
+			value = commandValue(value, *level.field)
+			prefix = sourceutil.MakePath(prefix, level.field.Name)
+		}
+		values[i] = value
+		prefixes[i] = prefix
+		if err := collectArgFields(value, prefix, &bound[i]); err != nil {
+			return err
+		}
+	}
+	if source.positional != nil && !source.dryRun {
+		*source.positional = levels[len(levels)-1].positional
+	}
+	if source.command != nil && !source.dryRun {
+		*source.command = strings.Join(chosen, " ")
+	}
 	var collected []error
-	source.loadStruct(elem, argsMap, source.mode, &collected)
+	for i, level := range levels {
+		source.loadStruct(values[i], level.args, source.mode, &collected, prefixes[i], "")
+		source.loadArgs(bound[i], level.positional, source.mode, &collected)
+	}
 	if len(collected) > 0 {
 		return setup.NewAggregatedLoadFailedError(errors.Join(collected...))
 	}
 	return nil
 }
 
-func parseArguments(args []string) map[string]string {
-	result := make(map[string]string)
-	i := 0
-	for i < len(args) {
-		token := args[i]
-		if strings.HasPrefix(token, "--") {
-			name := token[2:]
-			if name == "" {
-				i++
-				continue
-			}
-			if eq := strings.IndexByte(name, '='); eq >= 0 {
-				key := name[:eq]
-				value := name[eq+1:]
-				if strings.HasPrefix(key, "no-") {
-					k := key[3:]
-					result[k] = "false"
-				} else {
-					result[key] = value
-				}
-				i++
-				continue
-			}
-			if strings.HasPrefix(name, "no-") {
-				k := name[3:]
-				result[k] = "false"
-				i++
-				continue
-			}
-			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				result[name] = args[i+1]
-				i += 2
-				continue
-			}
-			result[name] = ""
-			i++
-			continue
-		}
-		if strings.HasPrefix(token, "-") && len(token) >= 2 {
-			name := token[1:]
-			if name == "" {
-				i++
-				continue
-			}
-			if eq := strings.IndexByte(name, '='); eq >= 0 {
-				key := name[:eq]
-				value := name[eq+1:]
-				result[key] = value
-				i++
-				continue
-			}
-			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				result[name] = args[i+1]
-				i += 2
-				continue
-			}
-			result[name] = ""
-			i++
-			continue
-		}
-		i++
+func (source Source) arguments() []string {
+	if source.explicitArgs {
+		return source.args
+	}
+	if len(os.Args) == 0 {
+		return nil
 	}
-	return result
+	return os.Args[1:]
 }
 
-func (source Source) loadStruct(structValue reflect.Value, args map[string]string, mode setup.LoadMode, errs *[]error) {
+func (source Source) loadStruct(structValue reflect.Value, args map[string][]argument, mode setup.LoadMode, errs *[]error, prefix string, namePrefix string) {
 	structType := structValue.Type()
 	for i := 0; i < structType.NumField(); i++ {
 		fieldInfo := structType.Field(i)
 		if fieldInfo.PkgPath != "" {
 			continue
 		}
+		if sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
 		fieldValue := structValue.Field(i)
-		if source.processLeafField(fieldValue, fieldInfo, args, mode, errs) {
+		if source.processLeafField(fieldValue, fieldInfo, args, mode, errs, prefix, namePrefix) {
 			continue
 		}
 		t := fieldInfo.Type
+		nestedPrefix := sourceutil.MakePath(prefix, fieldInfo.Name)
+		nestedNamePrefix := source.nestedNamePrefix(namePrefix, fieldInfo)
 		if t.Kind() == reflect.Struct {
-			source.loadStruct(fieldValue, args, mode, errs)
+			source.loadStruct(fieldValue, args, mode, errs, nestedPrefix, nestedNamePrefix)
 			continue
 		}
 		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
 			if fieldValue.IsNil() {
 				fieldValue.Set(reflect.New(t.Elem()))
 			}
-			source.loadStruct(fieldValue.Elem(), args, mode, errs)
+			source.loadStruct(fieldValue.Elem(), args, mode, errs, nestedPrefix, nestedNamePrefix)
 			continue
 		}
 	}
 }
 
-func (source Source) processLeafField(fieldValue reflect.Value, fieldInfo reflect.StructField, args map[string]string, mode setup.LoadMode, errs *[]error) bool {
+// nestedNamePrefix extends the long-name prefix with the flagSegment tag of a
+// nested struct field. Fields without the tag do not change the prefix.
+func (source Source) nestedNamePrefix(namePrefix string, fieldInfo reflect.StructField) string {
+	segment := fieldInfo.Tag.Get("flagSegment")
+	if segment == "" {
+		return namePrefix
+	}
+	return namePrefix + segment + source.separator
+}
+
+func (source Source) processLeafField(fieldValue reflect.Value, fieldInfo reflect.StructField, args map[string][]argument, mode setup.LoadMode, errs *[]error, prefix string, namePrefix string) bool {
 	tagFlag := fieldInfo.Tag.Get("flag")
 	if tagFlag == "" || tagFlag == "-" {
 		return false
 	}
+	tagFlag = namePrefix + tagFlag
 	tagShort := fieldInfo.Tag.Get("flagShort")
 	tagDefault := fieldInfo.Tag.Get("flagDefault")
-	v, ok := args[tagFlag]
-	usedShort := false
-	if !ok && tagShort != "" {
-		v, ok = args[tagShort]
-		usedShort = ok
+	occurrences := lookupOccurrences(args, tagFlag, tagShort)
+	ok := len(occurrences) > 0
+	path := sourceutil.MakePath(prefix, fieldInfo.Name)
+	name := tagFlag
+	if ok {
+		name = occurrences[len(occurrences)-1].name
 	}
+	event := setup.FieldEvent{Path: path, Key: flagKey(name, tagFlag), Found: ok}
 	if !sourceutil.ShouldAssign(fieldValue, ok, mode, tagDefault) {
+		sourceutil.Observe(source.observer, event)
 		return true
 	}
-	raw := ""
+	event.Eligible = true
+	t := fieldInfo.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var raws []string
 	if ok {
-		raw = v
-		if raw == "" {
-			t := fieldValue.Type()
-			if t.Kind() == reflect.Ptr {
-				t = t.Elem()
-			}
-			if t.Kind() == reflect.Bool {
-				raw = "true"
-			} else {
-				name := tagFlag
-				if usedShort {
-					name = tagShort
+		for _, occurrence := range occurrences {
+			raw := occurrence.value
+			if raw == "" {
+				if !isBoolFlag(t) {
+					parseErr := setup.ErrParseFailed{Type: t, Value: raw, Cause: setup.ErrEmptyValue}
+					*errs = append(*errs, fmt.Errorf("%s=%s: %w", occurrence.name, raw, parseErr))
+					event.Err = parseErr
+					sourceutil.Observe(source.observer, event)
+					return true
 				}
-				parseErr := setup.ErrParseFailed{Type: t, Value: raw, Cause: setup.ErrEmptyValue}
-				*errs = append(*errs, fmt.Errorf("%s=%s: %w", name, raw, parseErr))
-				return true
+				raw = "true"
 			}
+			raws = append(raws, raw)
 		}
 	} else {
 		if tagDefault == "" {
+			sourceutil.Observe(source.observer, event)
 			return true
 		}
-		raw = tagDefault
-	}
-	t := fieldInfo.Type
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	if t.Kind() == reflect.Slice || (t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Int) {
-		elemKind := t.Elem().Kind()
-		if elemKind == reflect.String || elemKind == reflect.Int {
-			delim := sourceutil.ResolveDelimiter(fieldInfo.Tag.Get("flagDelim"), source.delimiter)
-			raw = sourceutil.NormalizeDelimited(raw, delim)
-		}
+		raws = []string{tagDefault}
 	}
-	if err := sourceutil.AssignFromString(source.caster, fieldValue, raw); err != nil {
-		name := tagFlag
-		if usedShort {
-			name = tagShort
-		}
-		path := fieldInfo.Name
+	event.Default = !ok
+	if raw, err := source.assignRaws(fieldValue, fieldInfo, t, raws); err != nil {
 		*errs = append(*errs, setup.NewFlagsFieldFailedError(name, raw, path, err))
+		event.Err = err
+	} else {
+		event.Assigned = true
 	}
+	sourceutil.Observe(source.observer, event)
 	return true
 }
 
+// assignRaws stores the collected occurrences of a flag in fieldValue. On
+// failure it returns the raw value that was rejected alongside the error.
+func (source Source) assignRaws(fieldValue reflect.Value, fieldInfo reflect.StructField, t reflect.Type, raws []string) (string, error) {
+	if !sourceutil.IsListType(t) && len(raws) > 1 && source.repeat == RepeatError {
+		return raws[len(raws)-1], fmt.Errorf("%w: given %d times", setup.ErrFlagRepeated, len(raws))
+	}
+	delim := sourceutil.ResolveDelimiter(fieldInfo.Tag.Get("flagDelim"), source.delimiter)
+	return sourceutil.AssignRaws(source.caster, fieldValue, raws, delim)
+}
+
+// flagKey renders the name a flag was given under as it appears on the
+// command line, so that observers can tell -n from --name.
+func flagKey(name string, long string) string {
+	if name == long {
+		return "--" + name
+	}
+	return "-" + name
+}
+
+// isBoolFlag reports whether a flag may be given without a value, meaning
+// "true". Boolean slices accept bare occurrences too, one element each.
+func isBoolFlag(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
 // Removed local shouldSetField and setFieldValue in favor of common utilities.
@@ -0,0 +1,58 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/testcommon"
+)
+
+func TestFlagsSource_ExplicitArgs_IgnoresOSArgs(t *testing.T) {
+	old := osArgsSwap([]string{"app", "--name", "from-os", "--port", "1"})
+	defer osArgsSwap(old)
+
+	cfg := &testcommon.BasicTypesConfiguration{}
+	source := NewSourceWithArgs(setup.ModeOverride, []string{"--name", "explicit", "-d"})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "explicit", cfg.Name)
+	assert.Equal(t, 0, cfg.Port)
+	assert.Equal(t, true, cfg.Debug)
+}
+
+func TestFlagsSource_ExplicitArgs_EmptySliceMeansNoArguments(t *testing.T) {
+	old := osArgsSwap([]string{"app", "--name", "from-os"})
+	defer osArgsSwap(old)
+
+	cfg := &testcommon.BasicTypesConfiguration{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, nil).Load(cfg))
+	assert.Equal(t, "", cfg.Name)
+
+	require.NoError(t, NewSource(setup.ModeOverride).Load(cfg))
+	assert.Equal(t, "from-os", cfg.Name)
+}
+
+func TestFlagsSource_Options_Combine(t *testing.T) {
+	t.Parallel()
+	cfg := &FlagsDelimiterConfig{}
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"--ints", "1:2", "--strs", "a:b"}),
+		WithDelimiter(":"),
+		WithCaster(setup.NewTypeCaster()),
+		nil,
+	)
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, []int{1, 2}, cfg.Ints)
+	assert.Equal(t, []string{"a", "b"}, cfg.Strings)
+}
+
+func TestFlagsSource_ExplicitArgs_SubcommandTail(t *testing.T) {
+	t.Parallel()
+	args := []string{"migrate", "--port", "5432", "--name", "db"}
+	cfg := &testcommon.BasicTypesConfiguration{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, args[1:]).Load(cfg))
+	assert.Equal(t, 5432, cfg.Port)
+	assert.Equal(t, "db", cfg.Name)
+}
@@ -0,0 +1,120 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/testcommon"
+)
+
+type ClusterConfig struct {
+	Name    string `flag:"name" flagShort:"n"`
+	Port    int    `flag:"port" flagShort:"p"`
+	Level   int    `flag:"level" flagShort:"lv"`
+	Verbose bool   `flag:"verbose" flagShort:"v"`
+	Extract bool   `flag:"extract" flagShort:"x"`
+	Force   bool   `flag:"force" flagShort:"f"`
+}
+
+func TestFlagsSource_Cluster_BooleanShorts(t *testing.T) {
+	t.Parallel()
+	cfg := &ClusterConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"-vxf"}).Load(cfg))
+	assert.True(t, cfg.Verbose)
+	assert.True(t, cfg.Extract)
+	assert.True(t, cfg.Force)
+}
+
+func TestFlagsSource_Cluster_AttachedValues(t *testing.T) {
+	t.Parallel()
+	cfg := &ClusterConfig{}
+	source := NewSourceWithArgs(setup.ModeOverride, []string{"-p8080", "-vnservice", "-xp", "9090", "-lv", "3"})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "service", cfg.Name)
+	assert.Equal(t, 3, cfg.Level)
+	assert.True(t, cfg.Verbose)
+	assert.True(t, cfg.Extract)
+	assert.False(t, cfg.Force)
+}
+
+func TestFlagsSource_Cluster_MultiCharacterShortNamesMatchFirst(t *testing.T) {
+	t.Parallel()
+	cfg := &testcommon.CastConfiguration{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"-ip", "5"}).Load(cfg))
+	require.NotNil(t, cfg.IntPointer)
+	assert.Equal(t, 5, *cfg.IntPointer)
+}
+
+func TestFlagsSource_Cluster_UnknownCharacterKeepsSingleName(t *testing.T) {
+	t.Parallel()
+	cfg := &ClusterConfig{}
+	var positional []string
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"-vq", "value", "-v"}),
+		WithPositional(&positional),
+	)
+	require.NoError(t, source.Load(cfg))
+	assert.True(t, cfg.Verbose)
+	assert.False(t, cfg.Extract)
+	assert.Empty(t, positional)
+}
+
+func TestFlagsSource_Positional_TerminatorAndBareTokens(t *testing.T) {
+	t.Parallel()
+	cfg := &ClusterConfig{}
+	var positional []string
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"build", "-vx", "src", "--name=app", "-", "--", "--port", "1", "-f"}),
+		WithPositional(&positional),
+	)
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, []string{"build", "src", "-", "--port", "1", "-f"}, positional)
+	assert.Equal(t, "app", cfg.Name)
+	assert.True(t, cfg.Verbose)
+	assert.True(t, cfg.Extract)
+	assert.False(t, cfg.Force)
+	assert.Equal(t, 0, cfg.Port)
+}
+
+func TestFlagsSource_Positional_EmptyWhenNoneGiven(t *testing.T) {
+	t.Parallel()
+	positional := []string{"stale"}
+	source := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"--name", "x"}), WithPositional(&positional))
+	require.NoError(t, source.Load(&ClusterConfig{}))
+	assert.Empty(t, positional)
+}
+
+func TestFlagsSource_Positional_BoolFlagsDoNotTakeNextToken(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{name: "Short", args: []string{"-v", "file.txt"}},
+		{name: "Long", args: []string{"--verbose", "file.txt"}},
+		{name: "Cluster", args: []string{"-xv", "file.txt"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &ClusterConfig{}
+			var positional []string
+			source := NewSourceWithOptions(setup.ModeOverride, WithArgs(testCase.args), WithPositional(&positional))
+			require.NoError(t, source.Load(cfg))
+			assert.True(t, cfg.Verbose)
+			assert.Equal(t, []string{"file.txt"}, positional)
+		})
+	}
+
+	cfg := &ClusterConfig{Verbose: true}
+	var positional []string
+	source := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"--verbose=false", "-n", "app", "file.txt"}), WithPositional(&positional))
+	require.NoError(t, source.Load(cfg))
+	assert.False(t, cfg.Verbose)
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, []string{"file.txt"}, positional)
+}
@@ -0,0 +1,127 @@
+package flags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+type RepeatConfig struct {
+	IntPointer *[]int    `flag:"ip"`
+	Name       string    `flag:"name" flagShort:"n"`
+	Tags       []string  `flag:"tag" flagShort:"t"`
+	Ports      []int     `flag:"port" flagDelim:";"`
+	Ratios     []float64 `flag:"ratio"`
+	Flags      []bool    `flag:"flag"`
+	Bytes      []byte    `flag:"bytes"`
+	Grid       [4]int    `flag:"grid"`
+	Level      int       `flag:"level"`
+}
+
+func TestFlagsSource_Repeat_AccumulatesSlices(t *testing.T) {
+	t.Parallel()
+	cfg := &RepeatConfig{}
+	source := NewSourceWithArgs(setup.ModeOverride, []string{
+		"--tag", "a", "-t", "b,c", "--tag=d",
+		"--port", "80;443", "--port", "8080",
+		"--ratio", "0.5", "--ratio", "1.5,2",
+		"--flag", "--flag=false",
+		"--grid", "1,2", "--grid", "3",
+		"--ip", "1", "--ip", "2",
+		"--bytes", "ab", "--bytes", "cd",
+	})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, cfg.Tags)
+	assert.Equal(t, []int{80, 443, 8080}, cfg.Ports)
+	assert.Equal(t, []float64{0.5, 1.5, 2}, cfg.Ratios)
+	assert.Equal(t, []bool{true, false}, cfg.Flags)
+	assert.Equal(t, [4]int{1, 2, 3, 0}, cfg.Grid)
+	require.NotNil(t, cfg.IntPointer)
+	assert.Equal(t, []int{1, 2}, *cfg.IntPointer)
+	assert.Equal(t, []byte("cd"), cfg.Bytes)
+}
+
+func TestFlagsSource_Repeat_ScalarLastWinsByDefault(t *testing.T) {
+	t.Parallel()
+	cfg := &RepeatConfig{}
+	source := NewSourceWithArgs(setup.ModeOverride, []string{"--name", "first", "-n", "second", "--level", "1", "--level", "2"})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "second", cfg.Name)
+	assert.Equal(t, 2, cfg.Level)
+
+	cfg = &RepeatConfig{}
+	source = NewSourceWithArgs(setup.ModeOverride, []string{"-n", "short", "--name", "long"})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "long", cfg.Name)
+}
+
+func TestFlagsSource_Repeat_ScalarErrorPolicy(t *testing.T) {
+	t.Parallel()
+	cfg := &RepeatConfig{}
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"--name", "first", "-n", "second", "--tag", "a", "--tag", "b", "--level", "3"}),
+		WithRepeatPolicy(RepeatError),
+	)
+	err := source.Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrFlagRepeated))
+	assert.Contains(t, err.Error(), "flags n=second field Name: flag repeated: given 2 times")
+	assert.Equal(t, "", cfg.Name)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, 3, cfg.Level)
+}
+
+func TestFlagsSource_Repeat_ElementErrors(t *testing.T) {
+	t.Parallel()
+	cfg := &RepeatConfig{}
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"--ratio", "1", "--ratio", "x", "--port", "1", "--port", "y"}).Load(cfg)
+	require.Error(t, err)
+	var parseErr setup.ErrParseFailed
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Contains(t, err.Error(), "flags ratio=x field Ratios")
+	assert.Contains(t, err.Error(), "flags port=1,y field Ports")
+
+	err = NewSourceWithArgs(setup.ModeOverride, []string{"--tag", "a", "--tag"}).Load(&RepeatConfig{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrEmptyValue))
+}
+
+func TestFlagsSource_Repeat_EmptyOccurrencesAndDefaults(t *testing.T) {
+	t.Parallel()
+	type DefaultsConfig struct {
+		Tags   []string  `flag:"tag" flagDefault:"x,y"`
+		Ratios []float64 `flag:"ratio" flagDefault:"0.1"`
+	}
+	cfg := &DefaultsConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, nil).Load(cfg))
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags)
+	assert.Equal(t, []float64{0.1}, cfg.Ratios)
+
+	cfg = &DefaultsConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"--tag= ", "--ratio= "}).Load(cfg))
+	assert.Equal(t, []string{}, cfg.Tags)
+	assert.Equal(t, []float64{}, cfg.Ratios)
+}
+
+func TestFlagsSource_WithFieldObserver_ReportsGivenName(t *testing.T) {
+	t.Parallel()
+	type ObservedConfig struct {
+		Name  string   `flag:"name" flagShort:"n"`
+		Files []string `arg:"rest"`
+		Level int      `flag:"level" flagDefault:"3"`
+	}
+	var events []setup.FieldEvent
+	source := NewSourceWithArgs(setup.ModeOverride, []string{"-n", "x", "a.txt"}).WithFieldObserver(setup.FieldObserverFunc(func(event setup.FieldEvent) {
+		events = append(events, event)
+	}))
+	require.NoError(t, source.Load(&ObservedConfig{}))
+	assert.Equal(t, []setup.FieldEvent{
+		{Path: "Name", Key: "-n", Found: true, Eligible: true, Assigned: true},
+		{Path: "Level", Key: "--level", Default: true, Eligible: true, Assigned: true},
+		{Path: "Files", Key: "arg:rest", Found: true, Eligible: true, Assigned: true},
+	}, events)
+}
@@ -0,0 +1,92 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+type SegmentEndpoint struct {
+	Host string `flag:"host" flagUsage:"Host name"`
+	Port int    `flag:"port" flagShort:"p"`
+}
+
+type SegmentTLS struct {
+	CertFile string `flag:"cert"`
+}
+
+type SegmentConfig struct {
+	Cache *SegmentEndpoint `flagSegment:"cache"`
+	Plain struct {
+		Name string `flag:"name"`
+	}
+	Database struct {
+		TLS SegmentTLS `flagSegment:"tls"`
+		SegmentEndpoint
+	} `flagSegment:"db"`
+}
+
+func TestFlagsSource_Segments_DottedNames(t *testing.T) {
+	t.Parallel()
+	cfg := &SegmentConfig{}
+	source := NewSourceWithArgs(setup.ModeOverride, []string{
+		"--db.host", "db.local", "--db.port", "5432", "--db.tls.cert", "/cert.pem",
+		"--cache.host", "cache.local", "--name", "plain",
+	})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "db.local", cfg.Database.Host)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "/cert.pem", cfg.Database.TLS.CertFile)
+	require.NotNil(t, cfg.Cache)
+	assert.Equal(t, "cache.local", cfg.Cache.Host)
+	assert.Equal(t, 0, cfg.Cache.Port)
+	assert.Equal(t, "plain", cfg.Plain.Name)
+}
+
+func TestFlagsSource_Segments_DashedNames_And_UnprefixedShort(t *testing.T) {
+	t.Parallel()
+	cfg := &SegmentConfig{}
+	source := NewSourceWithOptions(setup.ModeOverride,
+		WithArgs([]string{"--db-host", "db.local", "--cache-host", "cache.local", "-p", "7"}),
+		WithSegmentSeparator("-"),
+	)
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "db.local", cfg.Database.Host)
+	assert.Equal(t, "cache.local", cfg.Cache.Host)
+	assert.Equal(t, 7, cfg.Database.Port)
+	assert.Equal(t, 7, cfg.Cache.Port)
+}
+
+func TestFlagsSource_Segments_ErrorShowsFullPath(t *testing.T) {
+	t.Parallel()
+	cfg := &SegmentConfig{}
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"--db.port", "x"}).Load(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "flags db.port=x field Database.SegmentEndpoint.Port")
+}
+
+func TestUsage_Segments(t *testing.T) {
+	t.Parallel()
+	source := NewSourceWithOptions(setup.ModeOverride, WithHelp("svc"), WithSegmentSeparator("-"))
+	usage, err := source.Usage(&SegmentConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, `Usage: svc [options]
+
+Cache:
+      --cache-host string   Host name
+  -p, --cache-port int
+
+Plain:
+      --name string
+
+Database.TLS:
+      --db-tls-cert string
+
+Database.SegmentEndpoint:
+      --db-host string      Host name
+  -p, --db-port int
+`, usage)
+}
@@ -0,0 +1,208 @@
+package flags
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+const restArg = "rest"
+
+// argField is a field bound to positional arguments by the arg tag.
+type argField struct {
+	value reflect.Value
+	path  string
+	field reflect.StructField
+	index int
+	rest  bool
+}
+
+// argFields holds the positional bindings of a target: the indexed fields in
+// declaration order and the optional field receiving the remaining arguments.
+type argFields struct {
+	rest    *argField
+	indexed []argField
+	count   int
+}
+
+// declaresArgs reports whether any field of structType, including fields of
+// nested structs, carries an arg tag.
+func declaresArgs(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" || sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
+		if tagArg := fieldInfo.Tag.Get("arg"); tagArg != "" && tagArg != "-" {
+			return true
+		}
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct && !sourceutil.IsTextUnmarshaler(t) && declaresArgs(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectArgFields finds every field tagged with arg:"N" or arg:"rest". Nil
+// pointers to nested structs are allocated the same way loadStruct does.
+func collectArgFields(structValue reflect.Value, prefix string, fields *argFields) error {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" || sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		path := sourceutil.MakePath(prefix, fieldInfo.Name)
+		if tagArg := fieldInfo.Tag.Get("arg"); tagArg != "" && tagArg != "-" {
+			if err := fields.add(argField{value: fieldValue, field: fieldInfo, path: path}, tagArg); err != nil {
+				return err
+			}
+			continue
+		}
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Struct && !sourceutil.IsTextUnmarshaler(t) {
+			if err := collectArgFields(fieldValue, path, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !sourceutil.IsTextUnmarshaler(t.Elem()) {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(t.Elem()))
+			}
+			if err := collectArgFields(fieldValue.Elem(), path, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (fields *argFields) add(field argField, tagArg string) error {
+	if tagArg == restArg {
+		if fields.rest != nil {
+			return setup.NewInvalidTargetError(fmt.Sprintf("fields %s and %s both use arg:%q", fields.rest.path, field.path, restArg))
+		}
+		if field.field.Type.Kind() != reflect.Slice || field.field.Type.Elem().Kind() == reflect.Uint8 {
+			return setup.NewInvalidTargetError(fmt.Sprintf("field %s with arg:%q must be a slice", field.path, restArg))
+		}
+		field.rest = true
+		fields.rest = &field
+		return nil
+	}
+	index, err := strconv.Atoi(tagArg)
+	if err != nil || index < 0 {
+		return setup.NewInvalidTargetError(fmt.Sprintf("field %s has invalid arg tag %q", field.path, tagArg))
+	}
+	for _, existing := range fields.indexed {
+		if existing.index == index {
+			return setup.NewInvalidTargetError(fmt.Sprintf("fields %s and %s both use arg:%q", existing.path, field.path, tagArg))
+		}
+	}
+	field.index = index
+	fields.indexed = append(fields.indexed, field)
+	if index+1 > fields.count {
+		fields.count = index + 1
+	}
+	return nil
+}
+
+// loadArgs assigns positional arguments to the collected fields. An indexed
+// field is required unless it has an argDefault tag, which may be empty. When
+// the target declares positional fields but none of them uses arg:"rest",
+// arguments beyond the highest index are reported as unexpected.
+func (source Source) loadArgs(fields argFields, positional []string, mode setup.LoadMode, errs *[]error) {
+	if len(fields.indexed) == 0 && fields.rest == nil {
+		return
+	}
+	for _, field := range fields.indexed {
+		source.loadIndexedArg(field, positional, mode, errs)
+	}
+	if fields.rest != nil {
+		var rest []string
+		if len(positional) > fields.count {
+			rest = positional[fields.count:]
+		}
+		source.loadRestArg(*fields.rest, rest, fields.count, mode, errs)
+		return
+	}
+	if len(positional) > fields.count {
+		*errs = append(*errs, setup.NewArgUnexpectedError(fields.count, positional[fields.count:]))
+	}
+}
+
+func (source Source) loadIndexedArg(field argField, positional []string, mode setup.LoadMode, errs *[]error) {
+	present := field.index < len(positional)
+	tagDefault, hasDefault := field.field.Tag.Lookup("argDefault")
+	event := setup.FieldEvent{Path: field.path, Key: "arg:" + strconv.Itoa(field.index), Found: present}
+	if !present && !hasDefault && (mode != setup.ModeFillMissing || field.value.IsZero()) {
+		*errs = append(*errs, setup.NewArgMissingError(field.index, field.path))
+		event.Err = setup.ErrArgMissing
+		sourceutil.Observe(source.observer, event)
+		return
+	}
+	if !sourceutil.ShouldAssign(field.value, present, mode, tagDefault) {
+		sourceutil.Observe(source.observer, event)
+		return
+	}
+	event.Eligible = true
+	event.Default = !present
+	raw := tagDefault
+	if present {
+		raw = positional[field.index]
+	}
+	delim := sourceutil.ResolveDelimiter(field.field.Tag.Get("argDelim"), source.delimiter)
+	failed, err := sourceutil.AssignRaws(source.caster, field.value, []string{raw}, delim)
+	if err != nil {
+		*errs = append(*errs, setup.NewArgFieldFailedError(field.index, failed, field.path, err))
+		event.Err = err
+	} else {
+		event.Assigned = true
+	}
+	sourceutil.Observe(source.observer, event)
+}
+
+// loadRestArg assigns each remaining argument to one element of the rest
+// field. Arguments are never split on a delimiter; argDefault is, because it
+// is a single tag value.
+func (source Source) loadRestArg(field argField, rest []string, start int, mode setup.LoadMode, errs *[]error) {
+	tagDefault := field.field.Tag.Get("argDefault")
+	present := len(rest) > 0
+	event := setup.FieldEvent{Path: field.path, Key: "arg:" + restArg, Found: present, Default: !present}
+	if !sourceutil.ShouldAssign(field.value, present, mode, tagDefault) {
+		event.Default = false
+		sourceutil.Observe(source.observer, event)
+		return
+	}
+	event.Eligible = true
+	if !present {
+		delim := sourceutil.ResolveDelimiter(field.field.Tag.Get("argDelim"), source.delimiter)
+		for _, token := range strings.Split(tagDefault, delim) {
+			rest = append(rest, strings.TrimSpace(token))
+		}
+	}
+	sliceType := field.field.Type
+	slice := reflect.MakeSlice(sliceType, 0, len(rest))
+	for offset, raw := range rest {
+		element := reflect.New(sliceType.Elem()).Elem()
+		if err := sourceutil.AssignFromString(source.caster, element, raw); err != nil {
+			*errs = append(*errs, setup.NewArgFieldFailedError(start+offset, raw, field.path, err))
+			event.Err = err
+			sourceutil.Observe(source.observer, event)
+			return
+		}
+		slice = reflect.Append(slice, element)
+	}
+	field.value.Set(slice)
+	event.Assigned = true
+	sourceutil.Observe(source.observer, event)
+}
@@ -0,0 +1,133 @@
+package flags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+type CopyConfig struct {
+	Source  string   `arg:"0"`
+	Target  string   `arg:"1"`
+	Extra   []string `arg:"rest"`
+	Verbose bool     `flag:"verbose" flagShort:"v"`
+}
+
+type ArgsConfig struct {
+	Ratio *float64 `arg:"2" argDefault:"0.5"`
+	Ports []int    `arg:"1" argDelim:";"`
+	Count int      `arg:"0"`
+}
+
+func TestFlagsSource_Args_BindsIndexedAndRest(t *testing.T) {
+	t.Parallel()
+	cfg := &CopyConfig{}
+	source := NewSourceWithArgs(setup.ModeOverride, []string{"a.txt", "-v", "b.txt", "--", "-c", "d,e"})
+	require.NoError(t, source.Load(cfg))
+	assert.Equal(t, "a.txt", cfg.Source)
+	assert.Equal(t, "b.txt", cfg.Target)
+	assert.Equal(t, []string{"-c", "d,e"}, cfg.Extra)
+	assert.True(t, cfg.Verbose)
+}
+
+func TestFlagsSource_Args_UsesTypeCasterAndDefaults(t *testing.T) {
+	t.Parallel()
+	cfg := &ArgsConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"3", "80;443"}).Load(cfg))
+	assert.Equal(t, 3, cfg.Count)
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	require.NotNil(t, cfg.Ratio)
+	assert.Equal(t, 0.5, *cfg.Ratio)
+
+	cfg = &ArgsConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"3", "80", "1.5"}).Load(cfg))
+	assert.Equal(t, 1.5, *cfg.Ratio)
+}
+
+func TestFlagsSource_Args_MissingRequired(t *testing.T) {
+	t.Parallel()
+	cfg := &CopyConfig{}
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"a.txt"}).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrArgMissing))
+	var missing *setup.ArgMissingError
+	require.True(t, errors.As(err, &missing))
+	assert.Equal(t, 1, missing.Index)
+	assert.Equal(t, "Target", missing.Path)
+	assert.Equal(t, "a.txt", cfg.Source)
+	assert.Empty(t, cfg.Extra)
+}
+
+func TestFlagsSource_Args_FillMissingKeepsPreviousValue(t *testing.T) {
+	t.Parallel()
+	cfg := &CopyConfig{Source: "x", Target: "y"}
+	require.NoError(t, NewSourceWithArgs(setup.ModeFillMissing, nil).Load(cfg))
+	assert.Equal(t, "x", cfg.Source)
+	assert.Equal(t, "y", cfg.Target)
+
+	err := NewSourceWithArgs(setup.ModeFillMissing, nil).Load(&CopyConfig{Source: "x"})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrArgMissing))
+}
+
+func TestFlagsSource_Args_TooMany(t *testing.T) {
+	t.Parallel()
+	cfg := &ArgsConfig{}
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"1", "2", "3", "x", "y"}).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrArgUnexpected))
+	var unexpected *setup.ArgUnexpectedError
+	require.True(t, errors.As(err, &unexpected))
+	assert.Equal(t, 3, unexpected.Index)
+	assert.Equal(t, []string{"x", "y"}, unexpected.Values)
+}
+
+func TestFlagsSource_Args_ConversionErrors(t *testing.T) {
+	t.Parallel()
+	err := NewSourceWithArgs(setup.ModeOverride, []string{"many", "1;x"}).Load(&ArgsConfig{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrSourceFieldFailed))
+	assert.Contains(t, err.Error(), "argument 0=many field Count")
+	assert.Contains(t, err.Error(), "argument 1=1,x field Ports")
+
+	type RestInts struct {
+		Values []int `arg:"rest"`
+	}
+	err = NewSourceWithArgs(setup.ModeOverride, []string{"1", "2", "z"}).Load(&RestInts{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "argument 2=z field Values")
+}
+
+func TestFlagsSource_Args_WithoutArgFieldsIgnoresPositionals(t *testing.T) {
+	t.Parallel()
+	cfg := &ClusterConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"build", "--name", "x", "extra"}).Load(cfg))
+	assert.Equal(t, "x", cfg.Name)
+}
+
+func TestFlagsSource_Args_InvalidTags(t *testing.T) {
+	t.Parallel()
+	type Duplicate struct {
+		A string `arg:"0"`
+		B string `arg:"0"`
+	}
+	type BadIndex struct {
+		A string `arg:"first"`
+	}
+	type ScalarRest struct {
+		A string `arg:"rest"`
+	}
+	type TwoRest struct {
+		A []string `arg:"rest"`
+		B []string `arg:"rest"`
+	}
+	for _, target := range []any{&Duplicate{}, &BadIndex{}, &ScalarRest{}, &TwoRest{}} {
+		err := NewSourceWithArgs(setup.ModeOverride, []string{"x"}).Load(target)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+	}
+}
@@ -0,0 +1,308 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type flagSpec struct {
+	long         string
+	short        string
+	defaultValue string
+	delimiter    string
+	usage        string
+	group        string
+	pathKind     string
+	values       []string
+	field        reflect.StructField
+}
+
+type flagGroup struct {
+	title string
+	specs []flagSpec
+}
+
+// Usage renders help text for every flag-tagged field of cfg. Fields of nested
+// structs are grouped under the nested field path. Descriptions come from the
+// flagUsage tag, or from desc when flagUsage is absent.
+func Usage(program string, cfg any) (string, error) {
+	source := NewSourceWithOptions(setup.ModeOverride)
+	source.program = program
+	return source.Usage(cfg)
+}
+
+// Usage renders help text for cfg using the program name and segment separator
+// configured on the source.
+func (source Source) Usage(cfg any) (string, error) {
+	structType, err := targetStructType(cfg)
+	if err != nil {
+		return "", err
+	}
+	commands, err := collectCommands(structType)
+	if err != nil {
+		return "", err
+	}
+	return renderUsage(source.programName(), source.collectFlagSpecs(structType, "", "", nil), commands), nil
+}
+
+func helpRequested(program string, specs []flagSpec, commands []commandSpec, args map[string][]argument) error {
+	claimed := make(map[string]bool, len(specs)*2)
+	for _, spec := range specs {
+		claimed[spec.long] = true
+		if spec.short != "" {
+			claimed[spec.short] = true
+		}
+	}
+	for _, name := range []string{"help", "h"} {
+		if _, ok := args[name]; ok && !claimed[name] {
+			return setup.NewHelpRequestedError(renderUsage(program, specs, commands))
+		}
+	}
+	return nil
+}
+
+func (source Source) programName() string {
+	if source.program != "" {
+		return source.program
+	}
+	if len(os.Args) > 0 {
+		return filepath.Base(os.Args[0])
+	}
+	return "app"
+}
+
+func targetStructType(cfg any) (reflect.Type, error) {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, setup.NewInvalidTargetError("target must be a struct or pointer to struct")
+	}
+	return t, nil
+}
+
+func (source Source) collectFlagSpecs(structType reflect.Type, group string, namePrefix string, specs []flagSpec) []flagSpec {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" || sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
+		tagFlag := fieldInfo.Tag.Get("flag")
+		if tagFlag == "-" {
+			continue
+		}
+		if tagFlag != "" {
+			specs = append(specs, flagSpec{
+				field:        fieldInfo,
+				long:         namePrefix + tagFlag,
+				short:        fieldInfo.Tag.Get("flagShort"),
+				defaultValue: fieldInfo.Tag.Get("flagDefault"),
+				delimiter:    fieldInfo.Tag.Get("flagDelim"),
+				usage:        fieldDescription(fieldInfo),
+				group:        group,
+				values:       enumValues(fieldInfo),
+				pathKind:     fieldInfo.Tag.Get("flagPath"),
+			})
+			continue
+		}
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			specs = source.collectFlagSpecs(t, sourceutil.MakePath(group, fieldInfo.Name), source.nestedNamePrefix(namePrefix, fieldInfo), specs)
+		}
+	}
+	return specs
+}
+
+// enumValues returns the allowed values declared by the flagEnum tag, which
+// separates them with "|".
+func enumValues(fieldInfo reflect.StructField) []string {
+	tagEnum := fieldInfo.Tag.Get("flagEnum")
+	if tagEnum == "" {
+		return nil
+	}
+	values := strings.Split(tagEnum, "|")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+func fieldDescription(fieldInfo reflect.StructField) string {
+	if usage := fieldInfo.Tag.Get("flagUsage"); usage != "" {
+		return usage
+	}
+	return fieldInfo.Tag.Get("desc")
+}
+
+func groupFlagSpecs(specs []flagSpec) []flagGroup {
+	groups := []flagGroup{{}}
+	index := map[string]int{"": 0}
+	for _, spec := range specs {
+		position, ok := index[spec.group]
+		if !ok {
+			position = len(groups)
+			index[spec.group] = position
+			groups = append(groups, flagGroup{title: spec.group})
+		}
+		groups[position].specs = append(groups[position].specs, spec)
+	}
+	if len(groups[0].specs) == 0 {
+		return groups[1:]
+	}
+	return groups
+}
+
+func renderUsage(program string, specs []flagSpec, commands []commandSpec) string {
+	var builder strings.Builder
+	builder.WriteString("Usage: ")
+	builder.WriteString(program)
+	if len(specs) > 0 {
+		builder.WriteString(" [options]")
+	}
+	if len(commands) > 0 {
+		builder.WriteString(" <command>")
+	}
+	builder.WriteString("\n")
+	renderCommands(&builder, commands)
+
+	shortWidth := 0
+	for _, spec := range specs {
+		if spec.short != "" && len(spec.short)+1 > shortWidth {
+			shortWidth = len(spec.short) + 1
+		}
+	}
+	leftWidth := 0
+	for _, spec := range specs {
+		if left := renderFlagNames(spec, shortWidth); len(left) > leftWidth {
+			leftWidth = len(left)
+		}
+	}
+
+	for _, group := range groupFlagSpecs(specs) {
+		builder.WriteString("\n")
+		if group.title == "" {
+			builder.WriteString("Options:\n")
+		} else {
+			builder.WriteString(group.title)
+			builder.WriteString(":\n")
+		}
+		for _, spec := range group.specs {
+			left := renderFlagNames(spec, shortWidth)
+			description := renderDescription(spec)
+			builder.WriteString("  ")
+			builder.WriteString(left)
+			if description != "" {
+				builder.WriteString(strings.Repeat(" ", leftWidth-len(left)+2))
+				builder.WriteString(description)
+			}
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
+
+func renderCommands(builder *strings.Builder, commands []commandSpec) {
+	if len(commands) == 0 {
+		return
+	}
+	width := 0
+	for _, command := range commands {
+		if len(command.name) > width {
+			width = len(command.name)
+		}
+	}
+	builder.WriteString("\nCommands:\n")
+	for _, command := range commands {
+		builder.WriteString("  ")
+		builder.WriteString(command.name)
+		if command.usage != "" {
+			builder.WriteString(strings.Repeat(" ", width-len(command.name)+2))
+			builder.WriteString(command.usage)
+		}
+		builder.WriteString("\n")
+	}
+}
+
+func renderFlagNames(spec flagSpec, shortWidth int) string {
+	var builder strings.Builder
+	if shortWidth > 0 {
+		if spec.short != "" {
+			builder.WriteString("-")
+			builder.WriteString(spec.short)
+			builder.WriteString(", ")
+			builder.WriteString(strings.Repeat(" ", shortWidth-len(spec.short)-1))
+		} else {
+			builder.WriteString(strings.Repeat(" ", shortWidth+2))
+		}
+	}
+	builder.WriteString("--")
+	builder.WriteString(spec.long)
+	if placeholder := valuePlaceholder(spec.field.Type); placeholder != "" {
+		builder.WriteString(" ")
+		builder.WriteString(placeholder)
+	}
+	return builder.String()
+}
+
+func renderDescription(spec flagSpec) string {
+	parts := make([]string, 0, 4)
+	if spec.usage != "" {
+		parts = append(parts, spec.usage)
+	}
+	if len(spec.values) > 0 {
+		parts = append(parts, "(one of: "+strings.Join(spec.values, ", ")+")")
+	}
+	if spec.delimiter != "" {
+		parts = append(parts, "(separated by "+strconv.Quote(spec.delimiter)+")")
+	}
+	if spec.defaultValue != "" {
+		parts = append(parts, "(default "+strconv.Quote(spec.defaultValue)+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+func valuePlaceholder(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return "duration"
+	}
+	if sourceutil.IsTextUnmarshaler(t) {
+		return "value"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return ""
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Complex64, reflect.Complex128:
+		return "complex"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "list"
+	default:
+		return "value"
+	}
+}
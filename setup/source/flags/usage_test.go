@@ -0,0 +1,99 @@
+package flags
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+type UsageDatabase struct {
+	Host string `flag:"db_host" flagUsage:"Database host" flagDefault:"localhost"`
+	Port int    `flag:"db_port" desc:"Database port"`
+}
+
+type UsageConfig struct {
+	Database *UsageDatabase
+	Name     string        `flag:"name" flagShort:"n" flagUsage:"Service name"`
+	Ignored  string        `flag:"-" flagUsage:"never shown"`
+	Tags     []string      `flag:"tag" flagDelim:";" flagUsage:"Tags"`
+	Untagged int           `flagUsage:"not a flag"`
+	Timeout  time.Duration `flag:"timeout"`
+	Workers  int           `flag:"workers" flagDefault:"4"`
+	Verbose  bool          `flag:"verbose" flagShort:"v" flagUsage:"Verbose output"`
+}
+
+const expectedUsage = `Usage: svc [options]
+
+Options:
+  -n, --name string       Service name
+      --tag list          Tags (separated by ";")
+      --timeout duration
+      --workers int       (default "4")
+  -v, --verbose           Verbose output
+
+Database:
+      --db_host string    Database host (default "localhost")
+      --db_port int       Database port
+`
+
+func TestUsage_RendersAlignedGroups(t *testing.T) {
+	usage, err := Usage("svc", &UsageConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, expectedUsage, usage)
+
+	byValue, err := Usage("svc", UsageConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, usage, byValue)
+}
+
+func TestUsage_NoFlags_And_InvalidTarget(t *testing.T) {
+	usage, err := Usage("svc", &struct{ A int }{})
+	require.NoError(t, err)
+	assert.Equal(t, "Usage: svc\n", usage)
+
+	_, err = Usage("svc", 5)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+
+	_, err = Usage("svc", nil)
+	require.Error(t, err)
+}
+
+func TestFlagsSource_Help_ReturnsSentinelWithUsage(t *testing.T) {
+	for _, helpFlag := range []string{"--help", "-h"} {
+		t.Run(helpFlag, func(t *testing.T) {
+			cfg := &UsageConfig{}
+			source := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"--name", "x", helpFlag}), WithHelp("svc"))
+			err := source.Load(cfg)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, setup.ErrHelpRequested))
+			var helpErr *setup.HelpRequestedError
+			require.True(t, errors.As(err, &helpErr))
+			assert.Equal(t, expectedUsage, helpErr.Usage)
+			assert.Equal(t, "", cfg.Name)
+		})
+	}
+}
+
+func TestFlagsSource_Help_DisabledByDefault_And_ClaimedNames(t *testing.T) {
+	cfg := &UsageConfig{}
+	require.NoError(t, NewSourceWithArgs(setup.ModeOverride, []string{"--help", "--name", "x"}).Load(cfg))
+	assert.Equal(t, "x", cfg.Name)
+
+	type HostConfig struct {
+		Host string `flag:"host" flagShort:"h"`
+	}
+	hostCfg := &HostConfig{}
+	source := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"-h", "example.org"}), WithHelp(""))
+	require.NoError(t, source.Load(hostCfg))
+	assert.Equal(t, "example.org", hostCfg.Host)
+
+	err := NewSourceWithOptions(setup.ModeOverride, WithArgs([]string{"--help"}), WithHelp("")).Load(hostCfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrHelpRequested))
+}
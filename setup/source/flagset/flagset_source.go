@@ -0,0 +1,329 @@
+// Package flagset bridges configuration structs and the standard library
+// flag package. Register defines the flag-tagged fields of a struct on a
+// *flag.FlagSet, and Source reads values back out of a parsed FlagSet, so
+// that setup.Loader can coexist with code that expects stdlib flags.
+package flagset
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+type Source struct {
+	caster    setup.TypeCaster
+	observer  setup.FieldObserver
+	flagSet   *flag.FlagSet
+	delimiter string
+	separator string
+	mode      setup.LoadMode
+}
+
+// Option configures Register and a Source created by NewSourceWithOptions.
+// Pass the same options to both so that they agree on flag names.
+type Option func(*options)
+
+type options struct {
+	caster    setup.TypeCaster
+	separator string
+}
+
+// WithCaster sets the TypeCaster used to check and convert values.
+func WithCaster(caster setup.TypeCaster) Option {
+	return func(o *options) {
+		if caster != nil {
+			o.caster = caster
+		}
+	}
+}
+
+// WithSegmentSeparator sets the separator placed between flagSegment names and
+// the leaf flag name, matching flags.WithSegmentSeparator. The default is ".".
+func WithSegmentSeparator(separator string) Option {
+	return func(o *options) {
+		o.separator = separator
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{caster: setup.NewTypeCaster(), separator: "."}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func NewSource(flagSet *flag.FlagSet, mode setup.LoadMode) *Source {
+	return NewSourceWithOptions(flagSet, mode)
+}
+
+func NewSourceWithCaster(flagSet *flag.FlagSet, mode setup.LoadMode, caster setup.TypeCaster) *Source {
+	return NewSourceWithOptions(flagSet, mode, WithCaster(caster))
+}
+
+func NewSourceWithOptions(flagSet *flag.FlagSet, mode setup.LoadMode, opts ...Option) *Source {
+	o := newOptions(opts)
+	return &Source{flagSet: flagSet, caster: o.caster, mode: sourceutil.DefaultMode(mode), delimiter: ",", separator: o.separator}
+}
+
+// WithFieldObserver returns a copy of the source that reports every
+// flag-tagged field to observer, keyed by the flag name.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
+// Load assigns the flags that were set on the command line and falls back to
+// flagDefault, or to the default a flag was defined with. Flags defined by
+// other code are read through flag.Getter when available, so their typed
+// values are kept; other values are converted from their string form.
+func (source Source) Load(cfg any) error {
+	elem, err := sourceutil.EnsureTargetStruct(cfg)
+	if err != nil {
+		return err
+	}
+	if source.flagSet == nil {
+		return setup.NewInvalidTargetError("flag set is nil")
+	}
+	set := make(map[string]bool)
+	source.flagSet.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	var collected []error
+	source.loadStruct(elem, set, &collected, "", "")
+	if len(collected) > 0 {
+		return setup.NewAggregatedLoadFailedError(errors.Join(collected...))
+	}
+	return nil
+}
+
+func (source Source) loadStruct(structValue reflect.Value, set map[string]bool, errs *[]error, prefix string, namePrefix string) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" || sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
+		fieldValue := structValue.Field(i)
+		if source.processLeafField(fieldValue, fieldInfo, set, errs, prefix, namePrefix) {
+			continue
+		}
+		t := fieldInfo.Type
+		nestedPrefix := sourceutil.MakePath(prefix, fieldInfo.Name)
+		nestedNamePrefix := nestedNamePrefix(namePrefix, fieldInfo, source.separator)
+		if t.Kind() == reflect.Struct {
+			source.loadStruct(fieldValue, set, errs, nestedPrefix, nestedNamePrefix)
+			continue
+		}
+		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(t.Elem()))
+			}
+			source.loadStruct(fieldValue.Elem(), set, errs, nestedPrefix, nestedNamePrefix)
+		}
+	}
+}
+
+func (source Source) processLeafField(fieldValue reflect.Value, fieldInfo reflect.StructField, set map[string]bool, errs *[]error, prefix string, namePrefix string) bool {
+	tagFlag := fieldInfo.Tag.Get("flag")
+	if tagFlag == "" || tagFlag == "-" {
+		return false
+	}
+	name := namePrefix + tagFlag
+	short := fieldInfo.Tag.Get("flagShort")
+	defined := source.flagSet.Lookup(name)
+	present := set[name]
+	if !present && short != "" && set[short] {
+		defined = source.flagSet.Lookup(short)
+		present = true
+	}
+	if defined == nil && short != "" {
+		defined = source.flagSet.Lookup(short)
+	}
+	tagDefault := fieldInfo.Tag.Get("flagDefault")
+	defaultValue := tagDefault
+	if defaultValue == "" && defined != nil {
+		defaultValue = defined.DefValue
+	}
+	path := sourceutil.MakePath(prefix, fieldInfo.Name)
+	key := name
+	if defined != nil && (present || tagDefault == "") {
+		key = defined.Name
+	}
+	event := setup.FieldEvent{Path: path, Key: "-" + key, Found: present}
+	if !sourceutil.ShouldAssign(fieldValue, present, source.mode, defaultValue) {
+		sourceutil.Observe(source.observer, event)
+		return true
+	}
+	event.Eligible = true
+	event.Default = !present
+	raw, err := source.assign(fieldValue, fieldInfo, defined, present, tagDefault)
+	if err != nil {
+		*errs = append(*errs, setup.NewFlagsFieldFailedError(key, raw, path, err))
+		event.Err = err
+	} else {
+		event.Assigned = true
+	}
+	sourceutil.Observe(source.observer, event)
+	return true
+}
+
+// assign stores the value of defined in fieldValue, or tagDefault when the
+// flag was not set and either carries no default of its own or is not
+// defined at all. On failure it returns the raw value that was rejected.
+func (source Source) assign(fieldValue reflect.Value, fieldInfo reflect.StructField, defined *flag.Flag, present bool, tagDefault string) (string, error) {
+	delim := sourceutil.ResolveDelimiter(fieldInfo.Tag.Get("flagDelim"), source.delimiter)
+	if !present && (tagDefault != "" || defined == nil) {
+		return sourceutil.AssignRaws(source.caster, fieldValue, []string{tagDefault}, delim)
+	}
+	switch value := defined.Value.(type) {
+	case *fieldFlag:
+		raws := value.raws
+		if !present {
+			raws = []string{defined.DefValue}
+		}
+		return sourceutil.AssignRaws(source.caster, fieldValue, raws, delim)
+	case flag.Getter:
+		return value.String(), sourceutil.AssignFromAny(source.caster, fieldValue, value.Get())
+	default:
+		return sourceutil.AssignRaws(source.caster, fieldValue, []string{defined.Value.String()}, delim)
+	}
+}
+
+func nestedNamePrefix(namePrefix string, fieldInfo reflect.StructField, separator string) string {
+	segment := fieldInfo.Tag.Get("flagSegment")
+	if segment == "" {
+		return namePrefix
+	}
+	return namePrefix + segment + separator
+}
+
+// fieldFlag is the flag.Value registered for a struct field. Set checks each
+// value with the TypeCaster and keeps the raw strings for Source to assign, so
+// parsing a FlagSet never writes into the struct itself.
+type fieldFlag struct {
+	caster    setup.TypeCaster
+	fieldType reflect.Type
+	delimiter string
+	raws      []string
+	isBool    bool
+}
+
+func (value *fieldFlag) String() string {
+	if value == nil || len(value.raws) == 0 {
+		return ""
+	}
+	return strings.Join(value.raws, value.delimiter)
+}
+
+func (value *fieldFlag) Set(raw string) error {
+	scratch := reflect.New(value.fieldType).Elem()
+	if _, err := sourceutil.AssignRaws(value.caster, scratch, []string{raw}, value.delimiter); err != nil {
+		return err
+	}
+	t := value.fieldType
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if sourceutil.IsListType(t) {
+		value.raws = append(value.raws, raw)
+	} else {
+		value.raws = []string{raw}
+	}
+	return nil
+}
+
+func (value *fieldFlag) Get() any {
+	return value.String()
+}
+
+// IsBoolFlag lets boolean fields be given as -name without a value.
+func (value *fieldFlag) IsBoolFlag() bool {
+	return value.isBool
+}
+
+// Register defines a flag on flagSet for every flag-tagged field of cfg,
+// including fields of nested structs, whose long names are prefixed with
+// flagSegment. A flagShort name is defined as a second flag sharing the same
+// value. The usage string comes from flagUsage or desc, and flagDefault is the
+// documented default. cfg is only inspected; values are assigned by Source.
+// Names that are already defined on flagSet, typically by other libraries, are
+// left alone, and Source reads the existing definition instead.
+func Register(flagSet *flag.FlagSet, cfg any) error {
+	return RegisterWithOptions(flagSet, cfg)
+}
+
+func RegisterWithCaster(flagSet *flag.FlagSet, cfg any, caster setup.TypeCaster) error {
+	return RegisterWithOptions(flagSet, cfg, WithCaster(caster))
+}
+
+func RegisterWithOptions(flagSet *flag.FlagSet, cfg any, opts ...Option) error {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return setup.NewInvalidTargetError("target must be a struct or pointer to struct")
+	}
+	if flagSet == nil {
+		return setup.NewInvalidTargetError("flag set is nil")
+	}
+	registerStruct(flagSet, t, newOptions(opts), "")
+	return nil
+}
+
+func registerStruct(flagSet *flag.FlagSet, structType reflect.Type, o options, namePrefix string) {
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" || sourceutil.IsCommandField(fieldInfo) {
+			continue
+		}
+		tagFlag := fieldInfo.Tag.Get("flag")
+		if tagFlag == "-" {
+			continue
+		}
+		if tagFlag != "" {
+			registerField(flagSet, fieldInfo, o.caster, namePrefix+tagFlag)
+			continue
+		}
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			registerStruct(flagSet, t, o, nestedNamePrefix(namePrefix, fieldInfo, o.separator))
+		}
+	}
+}
+
+func registerField(flagSet *flag.FlagSet, fieldInfo reflect.StructField, caster setup.TypeCaster, name string) {
+	t := fieldInfo.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	value := &fieldFlag{
+		caster:    caster,
+		fieldType: fieldInfo.Type,
+		delimiter: sourceutil.ResolveDelimiter(fieldInfo.Tag.Get("flagDelim"), ","),
+		isBool:    t.Kind() == reflect.Bool,
+	}
+	usage := fieldInfo.Tag.Get("flagUsage")
+	if usage == "" {
+		usage = fieldInfo.Tag.Get("desc")
+	}
+	names := []string{name}
+	if short := fieldInfo.Tag.Get("flagShort"); short != "" && short != name {
+		names = append(names, short)
+	}
+	for _, flagName := range names {
+		if flagSet.Lookup(flagName) != nil {
+			continue
+		}
+		flagSet.Var(value, flagName, usage)
+		flagSet.Lookup(flagName).DefValue = fieldInfo.Tag.Get("flagDefault")
+	}
+}
@@ -0,0 +1,156 @@
+package flagset
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/testcommon"
+)
+
+type FlagSetDatabase struct {
+	Host string `flag:"host" flagDefault:"localhost" flagUsage:"Database host"`
+	Port int    `flag:"port"`
+}
+
+type FlagSetConfig struct {
+	Database *FlagSetDatabase `flagSegment:"db"`
+	Timeout  *time.Duration   `flag:"timeout"`
+	Name     string           `flag:"name" flagShort:"n" desc:"Service name"`
+	Ignored  string           `flag:"-"`
+	Tags     []string         `flag:"tag" flagDelim:";"`
+	Ratios   []float64        `flag:"ratio"`
+	Verbose  bool             `flag:"verbose" flagShort:"v"`
+}
+
+func newFlagSet(t *testing.T, cfg any) *flag.FlagSet {
+	t.Helper()
+	flagSet := flag.NewFlagSet("svc", flag.ContinueOnError)
+	flagSet.SetOutput(&bytes.Buffer{})
+	flagSet.Duration("timeout", 3*time.Second, "defined by another library")
+	require.NoError(t, Register(flagSet, cfg))
+	return flagSet
+}
+
+func TestRegister_DefinesFlags(t *testing.T) {
+	t.Parallel()
+	flagSet := newFlagSet(t, &FlagSetConfig{})
+	for _, name := range []string{"db.host", "db.port", "name", "n", "tag", "ratio", "verbose", "v"} {
+		assert.NotNil(t, flagSet.Lookup(name), name)
+	}
+	assert.Nil(t, flagSet.Lookup("Ignored"))
+	assert.Equal(t, "Database host", flagSet.Lookup("db.host").Usage)
+	assert.Equal(t, "localhost", flagSet.Lookup("db.host").DefValue)
+	assert.Equal(t, "Service name", flagSet.Lookup("n").Usage)
+
+	assert.Equal(t, "defined by another library", flagSet.Lookup("timeout").Usage)
+	require.NoError(t, Register(flagSet, &FlagSetConfig{}))
+	assert.True(t, errors.Is(Register(flag.NewFlagSet("x", flag.ContinueOnError), 5), setup.ErrInvalidTarget))
+}
+
+func TestRegister_ParseValidatesWithTypeCaster(t *testing.T) {
+	t.Parallel()
+	cfg := &FlagSetConfig{}
+	flagSet := newFlagSet(t, cfg)
+	err := flagSet.Parse([]string{"-db.port", "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value "x" for flag -db.port`)
+	assert.Nil(t, cfg.Database)
+}
+
+func TestSource_ReadsParsedFlagSet(t *testing.T) {
+	t.Parallel()
+	cfg := &FlagSetConfig{}
+	flagSet := newFlagSet(t, cfg)
+	require.NoError(t, flagSet.Parse([]string{
+		"-v", "-n", "svc", "-db.port=5432", "-tag", "a;b", "-tag", "c",
+		"-ratio", "0.5", "-ratio", "1.5,2", "-timeout", "1m", "rest",
+	}))
+	require.NoError(t, NewSource(flagSet, setup.ModeOverride).Load(cfg))
+	assert.True(t, cfg.Verbose)
+	assert.Equal(t, "svc", cfg.Name)
+	require.NotNil(t, cfg.Database)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Tags)
+	assert.Equal(t, []float64{0.5, 1.5, 2}, cfg.Ratios)
+	require.NotNil(t, cfg.Timeout)
+	assert.Equal(t, time.Minute, *cfg.Timeout)
+	assert.Equal(t, []string{"rest"}, flagSet.Args())
+}
+
+func TestSource_DefaultsOfForeignFlags(t *testing.T) {
+	t.Parallel()
+	cfg := &FlagSetConfig{}
+	flagSet := newFlagSet(t, cfg)
+	require.NoError(t, flagSet.Parse(nil))
+	require.NoError(t, NewSource(flagSet, setup.ModeOverride).Load(cfg))
+	require.NotNil(t, cfg.Timeout)
+	assert.Equal(t, 3*time.Second, *cfg.Timeout)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, "", cfg.Name)
+}
+
+func TestSource_Mode(t *testing.T) {
+	t.Parallel()
+	flagSet := flag.NewFlagSet("svc", flag.ContinueOnError)
+	require.NoError(t, Register(flagSet, &testcommon.ModeBehaviorConfiguration{}))
+	require.NoError(t, flagSet.Parse([]string{"-a", "10", "-b", "20"}))
+
+	cfg := &testcommon.ModeBehaviorConfiguration{A: 5, B: testcommon.IntPointer(7)}
+	require.NoError(t, NewSource(flagSet, setup.ModeFillMissing).Load(cfg))
+	assert.Equal(t, 5, cfg.A)
+	assert.Equal(t, 7, *cfg.B)
+
+	require.NoError(t, NewSource(flagSet, setup.ModeOverride).Load(cfg))
+	assert.Equal(t, 10, cfg.A)
+	assert.Equal(t, 20, *cfg.B)
+}
+
+func TestSource_ForeignStringFlagErrors(t *testing.T) {
+	t.Parallel()
+	type Config struct {
+		Port int `flag:"port"`
+	}
+	flagSet := flag.NewFlagSet("svc", flag.ContinueOnError)
+	flagSet.String("port", "", "defined elsewhere as a string")
+	require.NoError(t, flagSet.Parse([]string{"-port", "x"}))
+	err := NewSource(flagSet, setup.ModeOverride).Load(&Config{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrSourceFieldFailed))
+	assert.Contains(t, err.Error(), "flags port=x field Port")
+
+	assert.True(t, errors.Is(NewSource(nil, setup.ModeOverride).Load(&Config{}), setup.ErrInvalidTarget))
+}
+
+func TestSource_WithLoader(t *testing.T) {
+	t.Parallel()
+	cfg := &FlagSetConfig{}
+	flagSet := newFlagSet(t, cfg)
+	require.NoError(t, flagSet.Parse([]string{"-name", "from-flags"}))
+	loader := setup.NewLoader(NewSource(flagSet, setup.ModeOverride))
+	require.NoError(t, loader.Load(cfg))
+	assert.Equal(t, "from-flags", cfg.Name)
+}
+
+func TestSource_SegmentSeparatorOption(t *testing.T) {
+	t.Parallel()
+	cfg := &FlagSetConfig{}
+	flagSet := flag.NewFlagSet("svc", flag.ContinueOnError)
+	flagSet.SetOutput(&bytes.Buffer{})
+	require.NoError(t, RegisterWithOptions(flagSet, cfg, WithSegmentSeparator("-")))
+	assert.NotNil(t, flagSet.Lookup("db-port"))
+	assert.Nil(t, flagSet.Lookup("db.port"))
+	require.NoError(t, flagSet.Parse([]string{"-db-port", "5432", "-db-host", "primary"}))
+
+	require.NoError(t, NewSourceWithOptions(flagSet, setup.ModeOverride, WithSegmentSeparator("-")).Load(cfg))
+	require.NotNil(t, cfg.Database)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "primary", cfg.Database.Host)
+}
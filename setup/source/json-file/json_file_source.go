@@ -11,14 +11,22 @@ import (
 )
 
 type Source struct {
-	path string
-	mode setup.LoadMode
+	observer setup.FieldObserver
+	path     string
+	mode     setup.LoadMode
 }
 
 func NewSource(path string, mode setup.LoadMode) *Source {
 	return &Source{path: path, mode: sourceutil.DefaultMode(mode)}
 }
 
+// WithFieldObserver returns a copy of the source that reports every leaf field
+// to observer, keyed by its dotted JSON key.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
 func (source Source) Load(cfg any) error {
 	elem, err := sourceutil.EnsureTargetStruct(cfg)
 	if err != nil {
@@ -40,11 +48,11 @@ func (source Source) Load(cfg any) error {
 		return setup.NewAggregatedLoadFailedError(err)
 	}
 	shadow := holder.Elem()
-	source.copyStructValues(elem, shadow, root, source.mode, nil, "")
+	source.copyStructValues(elem, shadow, root, source.mode, nil, "", "")
 	return nil
 }
 
-func (source Source) copyStructValues(dest reflect.Value, shadow reflect.Value, raw map[string]json.RawMessage, mode setup.LoadMode, _ *[]error, prefix string) {
+func (source Source) copyStructValues(dest reflect.Value, shadow reflect.Value, raw map[string]json.RawMessage, mode setup.LoadMode, _ *[]error, prefix string, keyPrefix string) {
 	structType := dest.Type()
 	for i := 0; i < structType.NumField(); i++ {
 		fieldInfo := structType.Field(i)
@@ -70,9 +78,11 @@ func (source Source) copyStructValues(dest reflect.Value, shadow reflect.Value,
 		}
 		destField := dest.Field(i)
 		shadowField := shadow.Field(i)
+		path := sourceutil.MakePath(prefix, fieldInfo.Name)
+		key := sourceutil.MakePath(keyPrefix, name)
 		t := fieldInfo.Type
 		if t.Kind() == reflect.Struct {
-			source.copyStructValues(destField, shadowField, childRaw, mode, nil, sourceutil.MakePath(prefix, fieldInfo.Name))
+			source.copyStructValues(destField, shadowField, childRaw, mode, nil, path, key)
 			continue
 		}
 		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
@@ -85,31 +95,41 @@ func (source Source) copyStructValues(dest reflect.Value, shadow reflect.Value,
 			} else {
 				shadowStruct = shadowField.Elem()
 			}
-			source.copyStructValues(destField.Elem(), shadowStruct, childRaw, mode, nil, sourceutil.MakePath(prefix, fieldInfo.Name))
-			continue
-		}
-		if !sourceutil.ShouldAssign(destField, present, mode, "") {
+			source.copyStructValues(destField.Elem(), shadowStruct, childRaw, mode, nil, path, key)
 			continue
 		}
-		if destField.Type() == shadowField.Type() {
-			destField.Set(shadowField)
-			continue
-		}
-		if destField.Type().Kind() == reflect.Ptr && shadowField.Type() == destField.Type().Elem() {
-			p := reflect.New(destField.Type().Elem())
-			p.Elem().Set(shadowField)
-			destField.Set(p)
-			continue
+		event := setup.FieldEvent{Path: path, Key: key, Found: present}
+		if sourceutil.ShouldAssign(destField, present, mode, "") {
+			event.Eligible = true
+			event.Assigned = assignShadow(destField, shadowField)
 		}
-		if shadowField.Type().Kind() == reflect.Ptr && shadowField.Type().Elem() == destField.Type() {
-			if shadowField.IsNil() {
-				destField.Set(reflect.Zero(destField.Type()))
-			} else {
-				destField.Set(shadowField.Elem())
-			}
-			continue
+		sourceutil.Observe(source.observer, event)
+	}
+}
+
+// assignShadow copies a decoded value into dest, adding or removing one level
+// of pointer indirection when the types differ by it. It reports whether the
+// value was copied.
+func assignShadow(destField reflect.Value, shadowField reflect.Value) bool {
+	if destField.Type() == shadowField.Type() {
+		destField.Set(shadowField)
+		return true
+	}
+	if destField.Type().Kind() == reflect.Ptr && shadowField.Type() == destField.Type().Elem() {
+		p := reflect.New(destField.Type().Elem())
+		p.Elem().Set(shadowField)
+		destField.Set(p)
+		return true
+	}
+	if shadowField.Type().Kind() == reflect.Ptr && shadowField.Type().Elem() == destField.Type() {
+		if shadowField.IsNil() {
+			destField.Set(reflect.Zero(destField.Type()))
+		} else {
+			destField.Set(shadowField.Elem())
 		}
+		return true
 	}
+	return false
 }
 
 func parseJSONTagName(tag string) string {
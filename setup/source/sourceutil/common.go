@@ -1,6 +1,7 @@
 package sourceutil
 
 import (
+	"encoding"
 	"reflect"
 	"strings"
 	"unicode"
@@ -268,6 +269,15 @@ func isNilAssignableKind(k reflect.Kind) bool {
 	}
 }
 
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// IsTextUnmarshaler reports whether the type or a pointer to it implements
+// encoding.TextUnmarshaler. Struct types such as time.Time that decode themselves
+// from text are treated as leaf values rather than nested structs.
+func IsTextUnmarshaler(t reflect.Type) bool {
+	return t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
 func MakePath(prefix, name string) string {
 	if prefix == "" {
 		return name
@@ -364,6 +374,73 @@ func ResolveDelimiter(tagDelimiter string, defaultDelimiter string) string {
 	return defaultDelimiter
 }
 
+// IsListType reports whether repeated command-line occurrences accumulate into
+// a field of type t instead of replacing each other. Byte slices and types
+// that decode themselves from text are treated as scalars.
+func IsListType(t reflect.Type) bool {
+	if IsTextUnmarshaler(t) {
+		return false
+	}
+	if t.Kind() == reflect.Slice {
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Int
+}
+
+// AssignRaws stores one or more raw values in field. Scalars take the last
+// value; slices and int arrays collect every delim-separated element of every
+// value. On failure it returns the raw value that could not be converted.
+func AssignRaws(caster setup.TypeCaster, field reflect.Value, raws []string, delim string) (string, error) {
+	t := field.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !IsListType(t) {
+		raw := raws[len(raws)-1]
+		return raw, AssignFromString(caster, field, raw)
+	}
+	elemKind := t.Elem().Kind()
+	if t.Kind() == reflect.Array || elemKind == reflect.String || elemKind == reflect.Int {
+		normalized := make([]string, 0, len(raws))
+		for _, raw := range raws {
+			if strings.TrimSpace(raw) != "" {
+				normalized = append(normalized, NormalizeDelimited(raw, delim))
+			}
+		}
+		raw := strings.Join(normalized, ",")
+		return raw, AssignFromString(caster, field, raw)
+	}
+	slice := reflect.MakeSlice(t, 0, len(raws))
+	for _, raw := range raws {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		for _, token := range strings.Split(raw, delim) {
+			element := reflect.New(t.Elem()).Elem()
+			token = strings.TrimSpace(token)
+			if err := AssignFromString(caster, element, token); err != nil {
+				return token, err
+			}
+			slice = reflect.Append(slice, element)
+		}
+	}
+	if field.Kind() == reflect.Ptr {
+		pointer := reflect.New(t)
+		pointer.Elem().Set(slice)
+		field.Set(pointer)
+		return "", nil
+	}
+	field.Set(slice)
+	return "", nil
+}
+
+// IsCommandField reports whether the field declares a subcommand with the cmd
+// tag. Command-line sources skip such fields when collecting flags.
+func IsCommandField(fieldInfo reflect.StructField) bool {
+	tagCmd := fieldInfo.Tag.Get("cmd")
+	return tagCmd != "" && tagCmd != "-"
+}
+
 func ConvertToEnvVar(name string) string {
 	var builder strings.Builder
 	builder.Grow(len(name))
@@ -441,3 +518,10 @@ func ConvertToEnvVar(name string) string {
 
 	return s
 }
+
+// Observe sends event to observer when one is set.
+func Observe(observer setup.FieldObserver, event setup.FieldEvent) {
+	if observer != nil {
+		observer.ObserveField(event)
+	}
+}
@@ -368,3 +368,31 @@ func TestAssignFromAny(t *testing.T) {
 		})
 	}
 }
+
+func TestAssignRaws(t *testing.T) {
+	caster := typecast.NewCaster()
+	var name string
+	raw, err := AssignRaws(caster, reflect.ValueOf(&name).Elem(), []string{"a", "b"}, ",")
+	require.NoError(t, err)
+	assert.Equal(t, "b", raw)
+	assert.Equal(t, "b", name)
+
+	var tags []string
+	_, err = AssignRaws(caster, reflect.ValueOf(&tags).Elem(), []string{"a;b", "c"}, ";")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, tags)
+
+	var ratios *[]float64
+	_, err = AssignRaws(caster, reflect.ValueOf(&ratios).Elem(), []string{"0.5", "1,x"}, ",")
+	require.Error(t, err)
+	assert.Nil(t, ratios)
+	raw, err = AssignRaws(caster, reflect.ValueOf(&ratios).Elem(), []string{"0.5", "1,2"}, ",")
+	require.NoError(t, err)
+	assert.Equal(t, "", raw)
+	require.NotNil(t, ratios)
+	assert.Equal(t, []float64{0.5, 1, 2}, *ratios)
+
+	assert.False(t, IsListType(reflect.TypeOf([]byte(nil))))
+	assert.False(t, IsListType(reflect.TypeOf(MyTextUnmarshaler{})))
+	assert.True(t, IsListType(reflect.TypeOf([3]int{})))
+}
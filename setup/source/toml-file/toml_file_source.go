@@ -0,0 +1,129 @@
+package tomlfile
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+var errExpectedTable = errors.New("expected a table")
+
+type Source struct {
+	observer setup.FieldObserver
+	path     string
+	mode     setup.LoadMode
+}
+
+func NewSource(path string, mode setup.LoadMode) *Source {
+	return &Source{path: path, mode: sourceutil.DefaultMode(mode)}
+}
+
+// WithFieldObserver returns a copy of the source that reports every leaf field
+// to observer, keyed by its dotted TOML key.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
+func (source Source) Load(cfg any) error {
+	elem, err := sourceutil.EnsureTargetStruct(cfg)
+	if err != nil {
+		return err
+	}
+
+	data, readErr := os.ReadFile(source.path)
+	if readErr != nil {
+		return setup.NewAggregatedLoadFailedError(readErr)
+	}
+
+	var root map[string]toml.Primitive
+	metadata, decodeErr := toml.Decode(string(data), &root)
+	if decodeErr != nil {
+		return setup.NewAggregatedLoadFailedError(decodeErr)
+	}
+
+	var collected []error
+	source.copyStructValues(elem, root, &metadata, source.mode, &collected, "", "")
+	if len(collected) > 0 {
+		return setup.NewAggregatedLoadFailedError(errors.Join(collected...))
+	}
+	return nil
+}
+
+func (source Source) copyStructValues(dest reflect.Value, table map[string]toml.Primitive, metadata *toml.MetaData, mode setup.LoadMode, errs *[]error, prefix string, keyPrefix string) {
+	structType := dest.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		name := parseTOMLTagName(fieldInfo.Tag.Get("toml"))
+		if name == "" {
+			continue
+		}
+		primitive, present := table[name]
+		destField := dest.Field(i)
+		path := sourceutil.MakePath(prefix, fieldInfo.Name)
+		key := sourceutil.MakePath(keyPrefix, name)
+		t := fieldInfo.Type
+		if isTable(t) {
+			var nested map[string]toml.Primitive
+			if present {
+				if nestedErr := metadata.PrimitiveDecode(primitive, &nested); nestedErr != nil || nested == nil {
+					*errs = append(*errs, setup.NewTOMLFieldFailedError(key, path, errExpectedTable))
+					continue
+				}
+			}
+			if t.Kind() == reflect.Ptr {
+				if destField.IsNil() {
+					if !present {
+						continue
+					}
+					destField.Set(reflect.New(t.Elem()))
+				}
+				destField = destField.Elem()
+			}
+			source.copyStructValues(destField, nested, metadata, mode, errs, path, key)
+			continue
+		}
+		event := setup.FieldEvent{Path: path, Key: key, Found: present}
+		if !sourceutil.ShouldAssign(destField, present, mode, "") {
+			sourceutil.Observe(source.observer, event)
+			continue
+		}
+		event.Eligible = true
+		holder := reflect.New(t)
+		if decodeErr := metadata.PrimitiveDecode(primitive, holder.Interface()); decodeErr != nil {
+			*errs = append(*errs, setup.NewTOMLFieldFailedError(key, path, decodeErr))
+			event.Err = decodeErr
+			sourceutil.Observe(source.observer, event)
+			continue
+		}
+		destField.Set(holder.Elem())
+		event.Assigned = true
+		sourceutil.Observe(source.observer, event)
+	}
+}
+
+func isTable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !sourceutil.IsTextUnmarshaler(t)
+}
+
+func parseTOMLTagName(tag string) string {
+	if tag == "" || tag == "-" {
+		return ""
+	}
+	if idx := strings.IndexByte(tag, ','); idx >= 0 {
+		return tag[:idx]
+	}
+	return tag
+}
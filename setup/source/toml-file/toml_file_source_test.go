@@ -0,0 +1,224 @@
+package tomlfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+func writeTOMLFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestTOML_BasicPrimitives(t *testing.T) {
+	type C struct {
+		Started time.Time `toml:"started"`
+		Name    string    `toml:"name"`
+		Port    int       `toml:"port"`
+		Ratio   float64   `toml:"ratio"`
+		Debug   bool      `toml:"debug"`
+	}
+	path := writeTOMLFile(t, `
+name = "hello"
+port = 8080
+ratio = 0.5
+debug = true
+started = 2024-01-02T03:04:05Z
+`)
+	cfg := &C{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", cfg.Name)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, true, cfg.Debug)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Started.UTC())
+}
+
+func TestTOML_Tables_To_NestedStructs(t *testing.T) {
+	type TLS struct {
+		CertFile string `toml:"cert_file"`
+		Enabled  bool   `toml:"enabled"`
+	}
+	type Server struct {
+		TLS  *TLS   `toml:"tls"`
+		Host string `toml:"host"`
+		Port int    `toml:"port"`
+	}
+	type Root struct {
+		Server Server `toml:"server"`
+	}
+	path := writeTOMLFile(t, `
+[server]
+host = "localhost"
+port = 443
+
+[server.tls]
+enabled = true
+cert_file = "/etc/cert.pem"
+`)
+	cfg := &Root{}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	assert.Equal(t, "localhost", cfg.Server.Host)
+	assert.Equal(t, 443, cfg.Server.Port)
+	require.NotNil(t, cfg.Server.TLS)
+	assert.Equal(t, true, cfg.Server.TLS.Enabled)
+	assert.Equal(t, "/etc/cert.pem", cfg.Server.TLS.CertFile)
+}
+
+func TestTOML_PointerStruct_AllocatedOnlyWhenPresent(t *testing.T) {
+	type Inner struct {
+		Value int `toml:"value"`
+	}
+	type Root struct {
+		Present *Inner `toml:"present"`
+		Absent  *Inner `toml:"absent"`
+	}
+	path := writeTOMLFile(t, "[present]\nvalue = 1\n")
+	cfg := &Root{}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	require.NotNil(t, cfg.Present)
+	assert.Equal(t, 1, cfg.Present.Value)
+	assert.Nil(t, cfg.Absent)
+}
+
+func TestTOML_ArrayOfTables_To_StructSlice(t *testing.T) {
+	type Upstream struct {
+		Name   string `toml:"name"`
+		Weight int    `toml:"weight"`
+	}
+	type Root struct {
+		Upstreams []Upstream `toml:"upstream"`
+		Ports     []int      `toml:"ports"`
+	}
+	path := writeTOMLFile(t, `
+ports = [80, 443]
+
+[[upstream]]
+name = "a"
+weight = 1
+
+[[upstream]]
+name = "b"
+weight = 2
+`)
+	cfg := &Root{}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	assert.Equal(t, []int{80, 443}, cfg.Ports)
+	assert.Equal(t, []Upstream{{Name: "a", Weight: 1}, {Name: "b", Weight: 2}}, cfg.Upstreams)
+}
+
+func TestTOML_Mode(t *testing.T) {
+	type C struct {
+		B *int `toml:"b"`
+		A int  `toml:"a"`
+	}
+	path := writeTOMLFile(t, "a = 10\nb = 20\n")
+
+	cfg := &C{A: 5, B: func(x int) *int { return &x }(7)}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	require.NotNil(t, cfg.B)
+	assert.Equal(t, 10, cfg.A)
+	assert.Equal(t, 20, *cfg.B)
+
+	cfg2 := &C{A: 5, B: func(x int) *int { return &x }(7)}
+	require.NoError(t, NewSource(path, setup.ModeFillMissing).Load(cfg2))
+	assert.Equal(t, 5, cfg2.A)
+	assert.Equal(t, 7, *cfg2.B)
+
+	cfg3 := &C{}
+	require.NoError(t, NewSource(path, 0).Load(cfg3))
+	require.NotNil(t, cfg3.B)
+	assert.Equal(t, 10, cfg3.A)
+	assert.Equal(t, 20, *cfg3.B)
+}
+
+func TestTOML_AggregatedErrors_WithKeyPaths(t *testing.T) {
+	type Root struct {
+		A     int `toml:"a"`
+		Outer struct {
+			Inner struct {
+				Value int `toml:"value"`
+			} `toml:"inner"`
+		} `toml:"outer"`
+		Table struct {
+			Value int `toml:"value"`
+		} `toml:"table"`
+		C int `toml:"c"`
+	}
+	path := writeTOMLFile(t, `
+a = "x"
+c = 3
+table = 5
+
+[outer.inner]
+value = "y"
+`)
+	cfg := &Root{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+	assert.True(t, errors.Is(err, setup.ErrSourceFieldFailed))
+	assert.Contains(t, err.Error(), "toml key a field A")
+	assert.Contains(t, err.Error(), "toml key outer.inner.value field Outer.Inner.Value")
+	assert.Contains(t, err.Error(), "toml key table field Table: expected a table")
+	assert.Equal(t, 3, cfg.C)
+
+	var fieldErr *setup.SourceFieldFailedError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "toml", fieldErr.SourceName)
+}
+
+func TestTOML_InvalidDocument(t *testing.T) {
+	type C struct {
+		Name string `toml:"name"`
+		Skip string `toml:"-"`
+	}
+	cfg := &C{}
+	require.NoError(t, NewSource(writeTOMLFile(t, "unused = 1\nSkip = \"x\"\n"), setup.ModeOverride).Load(cfg))
+	assert.Equal(t, "", cfg.Skip)
+
+	err := NewSource(writeTOMLFile(t, "name = \n"), setup.ModeOverride).Load(&C{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+
+	err = NewSource(filepath.Join(t.TempDir(), "missing.toml"), setup.ModeOverride).Load(&C{})
+	require.Error(t, err)
+
+	err = NewSource(writeTOMLFile(t, "name = \"a\"\n"), setup.ModeOverride).Load(C{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+}
+
+func TestTOML_WithFieldObserver_ReportsDottedKeys(t *testing.T) {
+	type C struct {
+		Server struct {
+			Host string `toml:"host"`
+			Port int    `toml:"port"`
+		} `toml:"server"`
+	}
+	path := writeTOMLFile(t, `
+[server]
+host = "example"
+`)
+	var events []setup.FieldEvent
+	source := NewSource(path, setup.ModeOverride).WithFieldObserver(setup.FieldObserverFunc(func(event setup.FieldEvent) {
+		events = append(events, event)
+	}))
+	require.NoError(t, source.Load(&C{}))
+	assert.Equal(t, []setup.FieldEvent{
+		{Path: "Server.Host", Key: "server.host", Found: true, Eligible: true, Assigned: true},
+		{Path: "Server.Port", Key: "server.port"},
+	}, events)
+}
@@ -0,0 +1,197 @@
+package yamlfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/sourceutil"
+)
+
+type Source struct {
+	observer setup.FieldObserver
+	path     string
+	mode     setup.LoadMode
+}
+
+func NewSource(path string, mode setup.LoadMode) *Source {
+	return &Source{path: path, mode: sourceutil.DefaultMode(mode)}
+}
+
+// WithFieldObserver returns a copy of the source that reports every leaf field
+// to observer, keyed by its dotted YAML key.
+func (source Source) WithFieldObserver(observer setup.FieldObserver) setup.Source {
+	source.observer = observer
+	return &source
+}
+
+func (source Source) Load(cfg any) error {
+	elem, err := sourceutil.EnsureTargetStruct(cfg)
+	if err != nil {
+		return err
+	}
+
+	data, readErr := os.ReadFile(source.path)
+	if readErr != nil {
+		return setup.NewAggregatedLoadFailedError(readErr)
+	}
+
+	var document yaml.Node
+	if unmarshalErr := yaml.Unmarshal(data, &document); unmarshalErr != nil {
+		return setup.NewAggregatedLoadFailedError(unmarshalErr)
+	}
+
+	root, rootErr := documentRoot(&document)
+	if rootErr != nil {
+		return setup.NewAggregatedLoadFailedError(rootErr)
+	}
+
+	var collected []error
+	source.copyStructValues(elem, root, source.mode, &collected, "", "")
+	if len(collected) > 0 {
+		return setup.NewAggregatedLoadFailedError(errors.Join(collected...))
+	}
+	return nil
+}
+
+func documentRoot(document *yaml.Node) (*yaml.Node, error) {
+	if document.Kind == 0 || len(document.Content) == 0 {
+		return nil, nil
+	}
+	root := resolveAlias(document.Content[0])
+	if isNull(root) {
+		return nil, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("yaml root at line %d, column %d must be a mapping", root.Line, root.Column)
+	}
+	return root, nil
+}
+
+func (source Source) copyStructValues(dest reflect.Value, node *yaml.Node, mode setup.LoadMode, errs *[]error, prefix string, keyPrefix string) {
+	structType := dest.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		name := parseYAMLTagName(fieldInfo.Tag.Get("yaml"))
+		if name == "" {
+			continue
+		}
+		child := lookupMappingValue(node, name)
+		present := child != nil
+		destField := dest.Field(i)
+		path := sourceutil.MakePath(prefix, fieldInfo.Name)
+		key := sourceutil.MakePath(keyPrefix, name)
+		t := fieldInfo.Type
+		if t.Kind() == reflect.Struct && !sourceutil.IsTextUnmarshaler(t) {
+			nested, nestedErr := nestedMapping(child, path)
+			if nestedErr != nil {
+				*errs = append(*errs, nestedErr)
+				continue
+			}
+			source.copyStructValues(destField, nested, mode, errs, path, key)
+			continue
+		}
+		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !sourceutil.IsTextUnmarshaler(t.Elem()) {
+			nested, nestedErr := nestedMapping(child, path)
+			if nestedErr != nil {
+				*errs = append(*errs, nestedErr)
+				continue
+			}
+			if destField.IsNil() {
+				destField.Set(reflect.New(t.Elem()))
+			}
+			source.copyStructValues(destField.Elem(), nested, mode, errs, path, key)
+			continue
+		}
+		event := setup.FieldEvent{Path: path, Key: key, Found: present}
+		if !sourceutil.ShouldAssign(destField, present, mode, "") {
+			sourceutil.Observe(source.observer, event)
+			continue
+		}
+		event.Eligible = true
+		holder := reflect.New(t)
+		if decodeErr := child.Decode(holder.Interface()); decodeErr != nil {
+			*errs = append(*errs, setup.NewYAMLFieldFailedError(path, child.Line, child.Column, decodeErr))
+			event.Err = decodeErr
+			sourceutil.Observe(source.observer, event)
+			continue
+		}
+		destField.Set(holder.Elem())
+		event.Assigned = true
+		sourceutil.Observe(source.observer, event)
+	}
+}
+
+func nestedMapping(node *yaml.Node, path string) (*yaml.Node, error) {
+	if node == nil || isNull(node) {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, setup.NewYAMLFieldFailedError(path, node.Line, node.Column, errors.New("expected a mapping"))
+	}
+	return node, nil
+}
+
+// lookupMappingValue returns the value of key name in a mapping. Keys written
+// in the mapping win over keys brought in with the merge key <<, whose value
+// is a mapping or a sequence of mappings searched in order.
+func lookupMappingValue(node *yaml.Node, name string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if isMergeKey(node.Content[i]) {
+			merged = append(merged, resolveAlias(node.Content[i+1]))
+			continue
+		}
+		if node.Content[i].Value == name {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	for _, value := range merged {
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, mapping := range sources {
+			if found := lookupMappingValue(resolveAlias(mapping), name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func isMergeKey(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Value == "<<" && node.ShortTag() == "!!merge"
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+func parseYAMLTagName(tag string) string {
+	if tag == "" || tag == "-" {
+		return ""
+	}
+	if idx := strings.IndexByte(tag, ','); idx >= 0 {
+		return tag[:idx]
+	}
+	return tag
+}
@@ -0,0 +1,230 @@
+package yamlfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sufir/go-set-me-up/setup"
+)
+
+func writeYAMLFile(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestYAML_BasicPrimitives(t *testing.T) {
+	type C struct {
+		Name  string  `yaml:"name"`
+		Port  int     `yaml:"port"`
+		Ratio float64 `yaml:"ratio"`
+		Debug bool    `yaml:"debug"`
+	}
+	path := writeYAMLFile(t, "name: hello\nport: 8080\nratio: 0.5\ndebug: true\n")
+	cfg := &C{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", cfg.Name)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, true, cfg.Debug)
+}
+
+func TestYAML_PointerLeaf_And_Collections(t *testing.T) {
+	type C struct {
+		IntPointer *int              `yaml:"int_pointer"`
+		Labels     map[string]string `yaml:"labels"`
+		Tags       []string          `yaml:"tags"`
+		Ports      [3]int            `yaml:"ports"`
+	}
+	path := writeYAMLFile(t, "int_pointer: 100\ntags: [a, b]\nlabels:\n  env: prod\nports: [1, 2, 3]\n")
+	cfg := &C{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.IntPointer)
+	assert.Equal(t, 100, *cfg.IntPointer)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, map[string]string{"env": "prod"}, cfg.Labels)
+	assert.Equal(t, [3]int{1, 2, 3}, cfg.Ports)
+}
+
+func TestYAML_NestedValue_And_Pointer(t *testing.T) {
+	type Inner struct {
+		Value int `yaml:"value"`
+	}
+	type Outer struct {
+		Inner *Inner `yaml:"inner"`
+		Plain Inner  `yaml:"plain"`
+	}
+	type Root struct {
+		Outer *Outer `yaml:"outer"`
+	}
+	path := writeYAMLFile(t, "outer:\n  inner:\n    value: 321\n  plain:\n    value: 123\n")
+	cfg := &Root{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Outer)
+	require.NotNil(t, cfg.Outer.Inner)
+	assert.Equal(t, 321, cfg.Outer.Inner.Value)
+	assert.Equal(t, 123, cfg.Outer.Plain.Value)
+}
+
+func TestYAML_TextUnmarshalerStruct_IsLeaf(t *testing.T) {
+	type C struct {
+		Started *time.Time `yaml:"started"`
+		Updated time.Time  `yaml:"updated"`
+	}
+	path := writeYAMLFile(t, "started: 2024-01-02T03:04:05Z\nupdated: 2024-02-03T04:05:06Z\n")
+	cfg := &C{}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	require.NotNil(t, cfg.Started)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), cfg.Started.UTC())
+	assert.Equal(t, time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC), cfg.Updated.UTC())
+}
+
+func TestYAML_Anchors(t *testing.T) {
+	type Endpoint struct {
+		Host string `yaml:"host"`
+	}
+	type C struct {
+		Primary   Endpoint `yaml:"primary"`
+		Secondary Endpoint `yaml:"secondary"`
+	}
+	path := writeYAMLFile(t, "primary: &endpoint\n  host: example.org\nsecondary: *endpoint\n")
+	cfg := &C{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "example.org", cfg.Primary.Host)
+	assert.Equal(t, "example.org", cfg.Secondary.Host)
+
+	type Database struct {
+		Host string `yaml:"host"`
+		User string `yaml:"user"`
+		Port int    `yaml:"port"`
+	}
+	type Merged struct {
+		Sequence *Database `yaml:"sequence"`
+		Literal  string    `yaml:"literal"`
+		Single   Database  `yaml:"single"`
+	}
+	content := "base: &base {host: h, port: 1}\n" +
+		"auth: &auth {user: admin, host: other}\n" +
+		"single:\n  <<: *base\n  port: 2\n" +
+		"sequence:\n  <<: [*auth, *base]\n" +
+		"literal: \"<<\"\n"
+	merged := &Merged{}
+	require.NoError(t, NewSource(writeYAMLFile(t, content), setup.ModeOverride).Load(merged))
+	assert.Equal(t, Database{Host: "h", Port: 2}, merged.Single)
+	require.NotNil(t, merged.Sequence)
+	assert.Equal(t, Database{Host: "other", User: "admin", Port: 1}, *merged.Sequence)
+	assert.Equal(t, "<<", merged.Literal)
+}
+
+func TestYAML_Mode(t *testing.T) {
+	type C struct {
+		B *int `yaml:"b"`
+		A int  `yaml:"a"`
+	}
+	path := writeYAMLFile(t, "a: 10\nb: 20\n")
+
+	cfg := &C{A: 5, B: func(x int) *int { return &x }(7)}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	require.NotNil(t, cfg.B)
+	assert.Equal(t, 10, cfg.A)
+	assert.Equal(t, 20, *cfg.B)
+
+	cfg2 := &C{A: 5, B: func(x int) *int { return &x }(7)}
+	require.NoError(t, NewSource(path, setup.ModeFillMissing).Load(cfg2))
+	require.NotNil(t, cfg2.B)
+	assert.Equal(t, 5, cfg2.A)
+	assert.Equal(t, 7, *cfg2.B)
+
+	cfg3 := &C{}
+	require.NoError(t, NewSource(path, setup.ModeFillMissing).Load(cfg3))
+	require.NotNil(t, cfg3.B)
+	assert.Equal(t, 10, cfg3.A)
+	assert.Equal(t, 20, *cfg3.B)
+}
+
+func TestYAML_UnknownKeysIgnored_And_EmptyDocument(t *testing.T) {
+	type C struct {
+		Name string `yaml:"name"`
+		Skip int    `yaml:"-"`
+		Port int    `yaml:"port,omitempty"`
+	}
+	path := writeYAMLFile(t, "unused: 42\nSkip: 7\nport: 9\n")
+	cfg := &C{}
+	require.NoError(t, NewSource(path, setup.ModeOverride).Load(cfg))
+	assert.Equal(t, "", cfg.Name)
+	assert.Equal(t, 0, cfg.Skip)
+	assert.Equal(t, 9, cfg.Port)
+
+	emptyPath := writeYAMLFile(t, "")
+	cfg2 := &C{Name: "kept"}
+	require.NoError(t, NewSource(emptyPath, setup.ModeOverride).Load(cfg2))
+	assert.Equal(t, "kept", cfg2.Name)
+}
+
+func TestYAML_AggregatedErrors_WithPosition(t *testing.T) {
+	type Root struct {
+		A     int `yaml:"a"`
+		Outer struct {
+			Inner struct {
+				Value int `yaml:"value"`
+			} `yaml:"inner"`
+		} `yaml:"outer"`
+		C int `yaml:"c"`
+	}
+	path := writeYAMLFile(t, "a: x\nouter:\n  inner:\n    value: y\nc: 3\n")
+	cfg := &Root{}
+	err := NewSource(path, setup.ModeOverride).Load(cfg)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+	assert.True(t, errors.Is(err, setup.ErrSourceFieldFailed))
+	assert.Contains(t, err.Error(), "yaml field A at line 1, column 4")
+	assert.Contains(t, err.Error(), "yaml field Outer.Inner.Value at line 4, column 12")
+	assert.Equal(t, 3, cfg.C)
+
+	var fieldErr *setup.SourceFieldFailedError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "yaml", fieldErr.SourceName)
+}
+
+func TestYAML_NestedNotMapping(t *testing.T) {
+	type Root struct {
+		Outer struct {
+			Value int `yaml:"value"`
+		} `yaml:"outer"`
+	}
+	path := writeYAMLFile(t, "outer: 5\n")
+	err := NewSource(path, setup.ModeOverride).Load(&Root{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "yaml field Outer at line 1, column 8: expected a mapping")
+}
+
+func TestYAML_InvalidDocument(t *testing.T) {
+	type C struct {
+		Name string `yaml:"name"`
+	}
+	err := NewSource(writeYAMLFile(t, "- a\n- b\n"), setup.ModeOverride).Load(&C{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrLoadAggregatedFailed))
+
+	err = NewSource(writeYAMLFile(t, "name: [unclosed\n"), setup.ModeOverride).Load(&C{})
+	require.Error(t, err)
+
+	err = NewSource(filepath.Join(t.TempDir(), "missing.yaml"), setup.ModeOverride).Load(&C{})
+	require.Error(t, err)
+
+	err = NewSource(writeYAMLFile(t, "name: a\n"), setup.ModeOverride).Load(C{})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, setup.ErrInvalidTarget))
+}
@@ -0,0 +1,40 @@
+package setup
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Load runs sources into a new T and returns it. Because the target is
+// allocated here, passing a non-pointer or nil target cannot happen. Go has
+// no constraint for struct types, so a T that is not a struct, including a
+// pointer to a struct, is still rejected at run time with an InvalidTarget
+// error. On failure the zero T is returned with the load error.
+func Load[T any](sources ...Source) (T, error) {
+	return LoadInto[T](NewLoader(sources...))
+}
+
+// LoadInto runs loader into a new T and returns it, with the same checks as
+// Load. Go methods cannot have type parameters, so this is a function taking
+// the loader rather than a loader.LoadInto[T] method.
+func LoadInto[T any](loader *Loader) (T, error) {
+	var value T
+	if targetType := reflect.TypeFor[T](); targetType.Kind() != reflect.Struct {
+		return value, NewInvalidTargetError(fmt.Sprintf("type %s is not a struct", targetType))
+	}
+	if err := loader.Load(&value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
+
+// MustLoad is like Load but panics when loading fails. It is meant for main,
+// where a service cannot start without its configuration.
+func MustLoad[T any](sources ...Source) T {
+	value, err := Load[T](sources...)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
@@ -0,0 +1,52 @@
+package setup_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+)
+
+func TestLoad_ReturnsTypedValue(t *testing.T) {
+	type TypedConfiguration struct {
+		Name string `env:"NAME"`
+		Port int    `env:"PORT" validate:"min=1"`
+	}
+	t.Setenv("APP_NAME", "service")
+	t.Setenv("APP_PORT", "8080")
+	environmentSource := env.NewSource("app", ",", pkg.ModeOverride)
+
+	configuration, loadError := pkg.Load[TypedConfiguration](environmentSource)
+	require.NoError(t, loadError)
+	assert.Equal(t, TypedConfiguration{Name: "service", Port: 8080}, configuration)
+
+	configuration, loadError = pkg.LoadInto[TypedConfiguration](pkg.NewLoader(environmentSource))
+	require.NoError(t, loadError)
+	assert.Equal(t, "service", configuration.Name)
+	assert.Equal(t, configuration, pkg.MustLoad[TypedConfiguration](environmentSource))
+
+	t.Setenv("APP_PORT", "0")
+	configuration, loadError = pkg.Load[TypedConfiguration](environmentSource)
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrValidationFailed))
+	assert.Equal(t, TypedConfiguration{}, configuration)
+	assert.Panics(t, func() {
+		pkg.MustLoad[TypedConfiguration](environmentSource)
+	})
+}
+
+func TestLoad_RejectsNonStructTypes(t *testing.T) {
+	environmentSource := env.NewSource("app", ",", pkg.ModeOverride)
+	_, loadError := pkg.Load[int]()
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrInvalidTarget))
+	assert.Contains(t, loadError.Error(), "type int is not a struct")
+
+	_, loadError = pkg.LoadInto[*struct{ Name string }](pkg.NewLoader(environmentSource))
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrInvalidTarget))
+}
@@ -0,0 +1,216 @@
+package setup
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the validate tags of the struct cfg points to, then runs the
+// Validator hooks of it and its nested structs, and returns every violation
+// joined into an AggregatedLoadFailedError. Loader.Load runs the same checks
+// after all sources have applied. Structs held in slices, arrays and maps are
+// checked too, under paths such as "Upstreams[0].Name" or
+// "Upstreams[main].Name".
+//
+// Rules are separated by commas: required, min=N, max=N, oneof=a|b|c and
+// regexp=PATTERN. min and max compare numbers by value and strings, slices
+// and maps by length. regexp must come last, since its pattern may contain
+// commas. Nil pointers only fail required; the other rules apply to the
+// value they point to.
+//
+// Cross-field rules name other fields of the same struct, by field name or
+// dotted path: requiredWith=A|B, excludedWith=A|B, and the comparisons
+// eqField, neField, ltField, lteField, gtField and gteField. Fields sharing a
+// oneOf:"group" tag form a group of which exactly one alternative must be
+// set; oneOf:"group:label" puts several fields into one alternative.
+func Validate(cfg any) error {
+	violations := append(validateTarget(cfg), runValidators(cfg)...)
+	if len(violations) > 0 {
+		return NewAggregatedLoadFailedError(errors.Join(violations...))
+	}
+	return nil
+}
+
+func validateTarget(cfg any) []error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	var violations []error
+	validateStruct(value.Elem(), "", &violations)
+	return violations
+}
+
+func validateStruct(structValue reflect.Value, prefix string, violations *[]error) {
+	structType := structValue.Type()
+	var groups oneOfGroups
+	for i := 0; i < structType.NumField(); i++ {
+		fieldInfo := structType.Field(i)
+		if fieldInfo.PkgPath != "" {
+			continue
+		}
+		path := fieldInfo.Name
+		if prefix != "" {
+			path = prefix + "." + fieldInfo.Name
+		}
+		fieldValue := structValue.Field(i)
+		if tag := fieldInfo.Tag.Get("validate"); tag != "" && tag != "-" {
+			validateField(structValue, prefix, fieldValue, path, tag, violations)
+		}
+		if tag := fieldInfo.Tag.Get("oneOf"); tag != "" && tag != "-" {
+			groups.add(tag, fieldInfo.Name, path, !isEmpty(fieldValue))
+		}
+		validateValue(fieldValue, path, violations)
+	}
+	groups.check(violations)
+}
+
+// validateValue checks the nested structs held by value, descending through
+// pointers and into the elements of slices, arrays and maps.
+func validateValue(value reflect.Value, path string, violations *[]error) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			validateValue(value.Elem(), path, violations)
+		}
+	case reflect.Struct:
+		if isNestedStruct(value.Type()) {
+			validateStruct(value, path, violations)
+		}
+	case reflect.Slice, reflect.Array:
+		if !holdsNestedStructs(value.Type().Elem()) {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			validateValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	case reflect.Map:
+		if !holdsNestedStructs(value.Type().Elem()) {
+			return
+		}
+		for _, key := range sortedMapKeys(value) {
+			validateValue(value.MapIndex(key), fmt.Sprintf("%s[%v]", path, key.Interface()), violations)
+		}
+	}
+}
+
+func validateField(structValue reflect.Value, prefix string, fieldValue reflect.Value, path string, tag string, violations *[]error) {
+	for _, rule := range splitRules(tag) {
+		name, argument, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if isEmpty(fieldValue) {
+				*violations = append(*violations, NewValidationFailedError(path, rule, "value is required"))
+			}
+			continue
+		}
+		if checker, ok := crossFieldRules[name]; ok {
+			if err := checker(crossFieldCheck{structValue: structValue, fieldValue: fieldValue, prefix: prefix, path: path, rule: rule, argument: argument}); err != nil {
+				*violations = append(*violations, err)
+			}
+			continue
+		}
+		value := fieldValue
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				break
+			}
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Ptr {
+			continue
+		}
+		if reason := checkRule(value, name, argument); reason != "" {
+			*violations = append(*violations, NewValidationFailedError(path, rule, reason))
+		}
+	}
+}
+
+// splitRules splits a validate tag on commas, keeping the remainder of the
+// tag after regexp= as a single pattern.
+func splitRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "regexp=") {
+			return append(rules, tag)
+		}
+		rule, rest, _ := strings.Cut(tag, ",")
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+		tag = strings.TrimLeft(rest, " ")
+	}
+	return rules
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// checkRule returns why value violates the rule, or "" when it satisfies it.
+func checkRule(value reflect.Value, name string, argument string) string {
+	switch name {
+	case "min", "max":
+		return checkBound(value, name, argument)
+	case "oneof":
+		actual := fmt.Sprint(value.Interface())
+		allowed := strings.Split(argument, "|")
+		for _, candidate := range allowed {
+			if actual == candidate {
+				return ""
+			}
+		}
+		return fmt.Sprintf("value %q is not one of %s", actual, strings.Join(allowed, ", "))
+	case "regexp":
+		if value.Kind() != reflect.String {
+			return fmt.Sprintf("regexp does not apply to %s", value.Type())
+		}
+		pattern, err := regexp.Compile(argument)
+		if err != nil {
+			return fmt.Sprintf("invalid pattern: %v", err)
+		}
+		if !pattern.MatchString(value.String()) {
+			return fmt.Sprintf("value %q does not match %s", value.String(), argument)
+		}
+		return ""
+	default:
+		return fmt.Sprintf("unknown rule %q", name)
+	}
+}
+
+func checkBound(value reflect.Value, name string, argument string) string {
+	bound, err := strconv.ParseFloat(argument, 64)
+	if err != nil {
+		return fmt.Sprintf("invalid bound %q", argument)
+	}
+	var actual float64
+	subject := "value"
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		actual = float64(value.Len())
+		subject = "length"
+	default:
+		return fmt.Sprintf("%s does not apply to %s", name, value.Type())
+	}
+	if name == "min" && actual < bound {
+		return fmt.Sprintf("%s %v is less than %s", subject, actual, argument)
+	}
+	if name == "max" && actual > bound {
+		return fmt.Sprintf("%s %v is greater than %s", subject, actual, argument)
+	}
+	return ""
+}
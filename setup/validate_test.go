@@ -0,0 +1,150 @@
+package setup_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkg "github.com/Sufir/go-set-me-up/setup"
+	"github.com/Sufir/go-set-me-up/setup/source/dict"
+	"github.com/Sufir/go-set-me-up/setup/source/env"
+	tomlfile "github.com/Sufir/go-set-me-up/setup/source/toml-file"
+)
+
+func TestLoader_Validate_CollectsEveryViolationAfterAllSources(t *testing.T) {
+	type ValidatedConfiguration struct {
+		Database *struct {
+			Host string `validate:"required"`
+		} `validate:"required"`
+		Timeout *int     `validate:"min=1"`
+		Level   string   `env:"LEVEL" validate:"oneof=debug|info|warn"`
+		Name    string   `env:"NAME" validate:"required,min=3,regexp=^[a-z]{1,8}$"`
+		Hosts   []string `env:"HOSTS" validate:"required,max=2"`
+		Port    int      `env:"PORT" validate:"min=1,max=65535"`
+		Ratio   float64  `validate:"max=1.5"`
+	}
+
+	t.Setenv("APP_PORT", "70000")
+	t.Setenv("APP_LEVEL", "trace")
+	t.Setenv("APP_NAME", "Ab")
+	t.Setenv("APP_HOSTS", "a,b,c")
+	loader := pkg.NewLoader(
+		env.NewSource("app", ",", pkg.ModeOverride),
+		dict.NewSource(map[string]any{"Ratio": 1.5}, pkg.ModeOverride),
+	)
+	loadError := loader.Load(&ValidatedConfiguration{})
+	require.Error(t, loadError)
+	assert.True(t, errors.Is(loadError, pkg.ErrLoadAggregatedFailed))
+	assert.True(t, errors.Is(loadError, pkg.ErrValidationFailed))
+	var validationError *pkg.ValidationFailedError
+	require.True(t, errors.As(loadError, &validationError))
+
+	message := loadError.Error()
+	assert.Contains(t, message, "field Database.Host: required: value is required")
+	assert.Contains(t, message, "field Level: oneof=debug|info|warn: value \"trace\" is not one of debug, info, warn")
+	assert.Contains(t, message, "field Name: min=3: length 2 is less than 3")
+	assert.Contains(t, message, "field Name: regexp=^[a-z]{1,8}$: value \"Ab\" does not match ^[a-z]{1,8}$")
+	assert.Contains(t, message, "field Hosts: max=2: length 3 is greater than 2")
+	assert.Contains(t, message, "field Port: max=65535: value 70000 is greater than 65535")
+	assert.NotContains(t, message, "Timeout")
+	assert.NotContains(t, message, "Ratio")
+
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_LEVEL", "info")
+	t.Setenv("APP_NAME", "abc")
+	t.Setenv("APP_HOSTS", "a")
+	timeout := 0
+	configuration := &ValidatedConfiguration{Timeout: &timeout}
+	configuration.Database = &struct {
+		Host string `validate:"required"`
+	}{Host: "db"}
+	loadError = loader.Load(configuration)
+	require.Error(t, loadError)
+	assert.Equal(t, 1, strings.Count(loadError.Error(), "validation failed"))
+	assert.Contains(t, loadError.Error(), "field Timeout: min=1: value 0 is less than 1")
+
+	timeout = 5
+	require.NoError(t, loader.Load(configuration))
+	require.NoError(t, pkg.Validate(configuration))
+
+	configuration.Database = nil
+	loadError = pkg.Validate(configuration)
+	require.Error(t, loadError)
+	assert.Contains(t, loadError.Error(), "field Database: required: value is required")
+	assert.NotContains(t, loadError.Error(), "Database.Host")
+}
+
+type ValidatedUpstream struct {
+	Name   string `toml:"name" validate:"required"`
+	Backup string `toml:"backup" oneOf:"target"`
+	Host   string `toml:"host" oneOf:"target"`
+	Weight int    `toml:"weight" validate:"gteField=Min"`
+	Min    int    `toml:"min"`
+}
+
+func (upstream *ValidatedUpstream) Validate() error {
+	if upstream.Weight > 100 {
+		return errors.New("weight too high")
+	}
+	return nil
+}
+
+type ValidatedUpstreams struct {
+	ByName map[string]ValidatedUpstream
+	Ups    []ValidatedUpstream `toml:"ups"`
+	Spare  [1]*ValidatedUpstream
+	Nested [][]ValidatedUpstream
+}
+
+func TestValidate_DescendsIntoCollections(t *testing.T) {
+	t.Parallel()
+	configuration := &ValidatedUpstreams{
+		Ups: []ValidatedUpstream{{Name: "a", Host: "h"}, {Host: "h", Weight: 1, Min: 2}},
+		ByName: map[string]ValidatedUpstream{
+			"main": {Name: "main"},
+			"ok":   {Name: "ok", Backup: "b"},
+		},
+		Spare:  [1]*ValidatedUpstream{{Name: "spare", Host: "h", Weight: 101}},
+		Nested: [][]ValidatedUpstream{{{Name: "n", Host: "h", Backup: "b"}}},
+	}
+	validateError := pkg.Validate(configuration)
+	require.Error(t, validateError)
+	message := validateError.Error()
+	assert.Contains(t, message, "field Ups[1].Name")
+	assert.Contains(t, message, "field Ups[1].Weight")
+	assert.Contains(t, message, "field ByName[main].Backup")
+	assert.Contains(t, message, "field Spare[0]: Validate: weight too high")
+	assert.Contains(t, message, "field Nested[0][0].Backup")
+	assert.NotContains(t, message, "Ups[0]")
+	assert.NotContains(t, message, "ByName[ok]")
+}
+
+func TestLoader_Validate_TOMLArrayOfTables(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(path, []byte("[[ups]]\nname = \"a\"\nhost = \"h\"\n\n[[ups]]\nhost = \"h\"\n"), 0o600))
+	loadError := pkg.NewLoader(tomlfile.NewSource(path, pkg.ModeOverride)).Load(&ValidatedUpstreams{})
+	require.Error(t, loadError)
+	assert.Contains(t, loadError.Error(), "field Ups[1].Name")
+	assert.NotContains(t, loadError.Error(), "Ups[0]")
+}
+
+func TestValidate_ReportsMisconfiguredRules(t *testing.T) {
+	type MisconfiguredConfiguration struct {
+		Pattern string `validate:"regexp=("`
+		Port    int    `validate:"min=low"`
+		Enabled bool   `validate:"max=1,between=1"`
+	}
+	validationError := pkg.Validate(&MisconfiguredConfiguration{})
+	require.Error(t, validationError)
+	message := validationError.Error()
+	assert.Contains(t, message, "field Pattern: regexp=(: invalid pattern")
+	assert.Contains(t, message, "field Port: min=low: invalid bound \"low\"")
+	assert.Contains(t, message, "field Enabled: max=1: max does not apply to bool")
+	assert.Contains(t, message, "field Enabled: between=1: unknown rule \"between\"")
+}